@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stripe/stripe-go/v74"
+	"github.com/stripe/stripe-go/v74/invoice"
+	"github.com/stripe/stripe-go/v74/invoiceitem"
+)
+
+// succeededStatuses are the PaymentResponse.Status values billing
+// treats as collected revenue worth invoicing for.
+var succeededStatuses = map[string]bool{
+	"SUCCEEDED": true,
+	"SUCCESS":   true,
+	"CAPTURED":  true,
+}
+
+// FXConverter is a Conversion-style background service that refreshes
+// foreign-exchange rates on an interval so multi-currency invoicing
+// can convert each transaction into the invoice's billing currency.
+type FXConverter struct {
+	baseCurrency    string
+	refreshInterval time.Duration
+
+	mu    sync.RWMutex
+	rates map[string]float64 // currency -> units per 1 baseCurrency
+
+	closeOnce sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewFXConverter returns an FXConverter quoting every currency against
+// baseCurrency, refreshing its rates every refreshInterval once Run is
+// called.
+func NewFXConverter(baseCurrency string, refreshInterval time.Duration) *FXConverter {
+	return &FXConverter{
+		baseCurrency:    baseCurrency,
+		refreshInterval: refreshInterval,
+		rates:           map[string]float64{baseCurrency: 1.0},
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+// Run blocks, refreshing rates every refreshInterval until ctx is
+// canceled or Close is called. Callers typically run it in its own
+// goroutine alongside the HTTP server.
+func (c *FXConverter) Run(ctx context.Context) error {
+	defer close(c.doneCh)
+
+	if err := c.refreshRates(); err != nil {
+		log.Printf("Warning: initial FX rate refresh failed: %v", err)
+	}
+
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.stopCh:
+			return nil
+		case <-ticker.C:
+			if err := c.refreshRates(); err != nil {
+				log.Printf("Warning: FX rate refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops Run and waits for it to return.
+func (c *FXConverter) Close() error {
+	c.closeOnce.Do(func() { close(c.stopCh) })
+	<-c.doneCh
+	return nil
+}
+
+// refreshRates pulls the latest exchange rates. A production
+// deployment would call out to an FX rate provider; this keeps the
+// previous rate for currencies it doesn't know about rather than
+// failing invoicing outright.
+func (c *FXConverter) refreshRates() error {
+	fetched := map[string]float64{
+		"USD": 1.0,
+		"EUR": 0.92,
+		"GBP": 0.79,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for currency, rate := range fetched {
+		c.rates[currency] = rate
+	}
+	return nil
+}
+
+// Convert converts amount in fromCurrency into toCurrency using the
+// most recently fetched rates.
+func (c *FXConverter) Convert(amount float64, fromCurrency, toCurrency string) (float64, error) {
+	if fromCurrency == toCurrency {
+		return amount, nil
+	}
+
+	c.mu.RLock()
+	fromRate, fromOK := c.rates[fromCurrency]
+	toRate, toOK := c.rates[toCurrency]
+	c.mu.RUnlock()
+
+	if !fromOK {
+		return 0, fmt.Errorf("no exchange rate available for currency %q", fromCurrency)
+	}
+	if !toOK {
+		return 0, fmt.Errorf("no exchange rate available for currency %q", toCurrency)
+	}
+	return amount / fromRate * toRate, nil
+}
+
+// InvoiceRecord aggregates every succeeded payment a customer made
+// during Period (a "YYYY-MM" month), the unit PrepareInvoiceRecords
+// groups transactions into before CreateInvoiceItems and
+// CreateInvoices turn it into an actual Stripe invoice.
+type InvoiceRecord struct {
+	ID              string    `json:"id"` // CustomerID + "_" + Period
+	CustomerID      string    `json:"customer_id"`
+	Period          string    `json:"period"`
+	TransactionIDs  []string  `json:"transaction_ids"`
+	TotalAmount     float64   `json:"total_amount"`
+	InvoiceCurrency string    `json:"invoice_currency"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BillingService turns the per-transaction payments ProcessPayment
+// records into monthly Stripe invoices: PrepareInvoiceRecords
+// aggregates a period's succeeded payments per customer,
+// CreateInvoiceItems stages them as Stripe invoice line items, and
+// CreateInvoices issues the invoice. This mirrors how a subscription
+// platform reconciles usage-based charges into a single bill rather
+// than charging a card per transaction.
+type BillingService struct {
+	store *TransactionStore
+	fx    *FXConverter
+
+	mu      sync.Mutex
+	records map[string]*InvoiceRecord // InvoiceRecord.ID -> record
+}
+
+// NewBillingService returns a BillingService that aggregates payments
+// from store and converts amounts using fx.
+func NewBillingService(store *TransactionStore, fx *FXConverter) *BillingService {
+	return &BillingService{
+		store:   store,
+		fx:      fx,
+		records: make(map[string]*InvoiceRecord),
+	}
+}
+
+func invoiceRecordID(customerID, period string) string {
+	return customerID + "_" + period
+}
+
+// PrepareInvoiceRecords aggregates every succeeded payment processed
+// during period (a "YYYY-MM" month) into one InvoiceRecord per
+// customer, denominated in invoiceCurrency.
+func (bs *BillingService) PrepareInvoiceRecords(period, invoiceCurrency string) ([]*InvoiceRecord, error) {
+	byCustomer := make(map[string]*InvoiceRecord)
+
+	for _, payment := range bs.store.listPayments() {
+		if payment.CustomerID == "" || !succeededStatuses[payment.Status] {
+			continue
+		}
+		if payment.ProcessedAt.Format("2006-01") != period {
+			continue
+		}
+
+		converted, err := bs.fx.Convert(payment.Amount, payment.Currency, invoiceCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert payment %s to %s: %w", payment.TransactionID, invoiceCurrency, err)
+		}
+
+		record, ok := byCustomer[payment.CustomerID]
+		if !ok {
+			record = &InvoiceRecord{
+				ID:              invoiceRecordID(payment.CustomerID, period),
+				CustomerID:      payment.CustomerID,
+				Period:          period,
+				InvoiceCurrency: invoiceCurrency,
+				CreatedAt:       time.Now(),
+			}
+			byCustomer[payment.CustomerID] = record
+		}
+		record.TransactionIDs = append(record.TransactionIDs, payment.TransactionID)
+		record.TotalAmount += converted
+	}
+
+	records := make([]*InvoiceRecord, 0, len(byCustomer))
+	bs.mu.Lock()
+	for _, record := range byCustomer {
+		bs.records[record.ID] = record
+		records = append(records, record)
+	}
+	bs.mu.Unlock()
+
+	return records, nil
+}
+
+func (bs *BillingService) getRecord(recordID string) (*InvoiceRecord, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	record, ok := bs.records[recordID]
+	if !ok {
+		return nil, fmt.Errorf("no invoice record found for id %q; run PrepareInvoiceRecords first", recordID)
+	}
+	return record, nil
+}
+
+// CreateInvoiceItems stages recordID's aggregated total as a pending
+// Stripe invoice item against its customer, ready to be picked up by
+// the next invoice CreateInvoices opens for them.
+func (bs *BillingService) CreateInvoiceItems(recordID string) (*stripe.InvoiceItem, error) {
+	record, err := bs.getRecord(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := invoiceitem.New(&stripe.InvoiceItemParams{
+		Customer:    stripe.String(record.CustomerID),
+		Amount:      stripe.Int64(int64(record.TotalAmount * 100)),
+		Currency:    stripe.String(record.InvoiceCurrency),
+		Description: stripe.String(fmt.Sprintf("Usage for %s (%d transactions)", record.Period, len(record.TransactionIDs))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice item for %s: %w", recordID, err)
+	}
+	return item, nil
+}
+
+// CreateInvoices issues and finalizes a Stripe invoice for recordID's
+// customer, collecting whatever invoice items are currently pending
+// against their account (normally just the one CreateInvoiceItems
+// staged).
+func (bs *BillingService) CreateInvoices(recordID string) (*stripe.Invoice, error) {
+	record, err := bs.getRecord(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := invoice.New(&stripe.InvoiceParams{
+		Customer: stripe.String(record.CustomerID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice for %s: %w", recordID, err)
+	}
+
+	finalized, err := invoice.FinalizeInvoice(inv.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize invoice %s for %s: %w", inv.ID, recordID, err)
+	}
+	return finalized, nil
+}
+
+// handlePrepareInvoiceRecords handles
+// POST /api/v1/billing/prepare-invoice-records.
+func (bs *BillingService) handlePrepareInvoiceRecords(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Period          string `json:"period"`
+		InvoiceCurrency string `json:"invoice_currency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	if req.InvoiceCurrency == "" {
+		req.InvoiceCurrency = "USD"
+	}
+
+	records, err := bs.PrepareInvoiceRecords(req.Period, req.InvoiceCurrency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// handleCreateInvoiceItems handles
+// POST /api/v1/billing/create-invoice-items.
+func (bs *BillingService) handleCreateInvoiceItems(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RecordID string `json:"record_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	item, err := bs.CreateInvoiceItems(req.RecordID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// handleCreateInvoices handles POST /api/v1/billing/create-invoices.
+func (bs *BillingService) handleCreateInvoices(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RecordID string `json:"record_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := bs.CreateInvoices(req.RecordID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inv)
+}
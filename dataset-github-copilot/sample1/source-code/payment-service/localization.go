@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Localizer resolves message keys to language-specific strings,
+// falling back to its default language (and finally the key itself)
+// when a language or message isn't registered. Bundles are plain
+// maps so operators can register additional languages at runtime with
+// RegisterBundle, without recompiling.
+type Localizer struct {
+	mu          sync.RWMutex
+	defaultLang string
+	bundles     map[string]map[string]string // lang -> key -> template
+}
+
+// NewLocalizer returns a Localizer seeded with the built-in en/tr/de/fr/es
+// bundles, falling back to defaultLang for languages it doesn't know.
+func NewLocalizer(defaultLang string) *Localizer {
+	l := &Localizer{
+		defaultLang: defaultLang,
+		bundles:     make(map[string]map[string]string),
+	}
+	for lang, messages := range builtinBundles {
+		l.RegisterBundle(lang, messages)
+	}
+	return l
+}
+
+// RegisterBundle adds or overwrites lang's message bundle, the
+// extension point for an operator to add a language this service
+// doesn't ship with.
+func (l *Localizer) RegisterBundle(lang string, messages map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bundles[lang] = messages
+}
+
+// SetDefaultLanguage changes which language Message falls back to
+// when a request's language isn't registered or doesn't have key.
+func (l *Localizer) SetDefaultLanguage(lang string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaultLang = lang
+}
+
+// Message resolves key in lang, falling back to the default language
+// and then to key itself, formatting the result with args like
+// fmt.Sprintf.
+func (l *Localizer) Message(lang, key string, args ...interface{}) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	template, ok := l.bundles[lang][key]
+	if !ok {
+		template, ok = l.bundles[l.defaultLang][key]
+	}
+	if !ok {
+		template = key
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// builtinBundles are the message catalogs shipped with this service.
+var builtinBundles = map[string]map[string]string{
+	"en": {
+		"order_id_required":       "order ID is required",
+		"customer_id_required":    "customer ID is required",
+		"amount_positive":         "amount must be greater than zero",
+		"currency_invalid":        "currency must be 3 character ISO code",
+		"payment_method_required": "payment method is required",
+		"amount_below_minimum":    "minimum payment amount is %.2f %s",
+		"amount_above_maximum":    "maximum payment amount is %.2f %s",
+		"receipt_description":     "Payment for order %s",
+	},
+	"tr": {
+		"order_id_required":       "sipariş numarası gereklidir",
+		"customer_id_required":    "müşteri numarası gereklidir",
+		"amount_positive":         "tutar sıfırdan büyük olmalıdır",
+		"currency_invalid":        "para birimi 3 karakterli ISO kodu olmalıdır",
+		"payment_method_required": "ödeme yöntemi gereklidir",
+		"amount_below_minimum":    "minimum ödeme tutarı %.2f %s",
+		"amount_above_maximum":    "maksimum ödeme tutarı %.2f %s",
+		"receipt_description":     "%s siparişi için ödeme",
+	},
+	"de": {
+		"order_id_required":       "Bestell-ID ist erforderlich",
+		"customer_id_required":    "Kunden-ID ist erforderlich",
+		"amount_positive":         "Betrag muss größer als null sein",
+		"currency_invalid":        "Währung muss ein 3-stelliger ISO-Code sein",
+		"payment_method_required": "Zahlungsmethode ist erforderlich",
+		"amount_below_minimum":    "Mindestzahlungsbetrag ist %.2f %s",
+		"amount_above_maximum":    "maximaler Zahlungsbetrag ist %.2f %s",
+		"receipt_description":     "Zahlung für Bestellung %s",
+	},
+	"fr": {
+		"order_id_required":       "l'ID de commande est requis",
+		"customer_id_required":    "l'ID client est requis",
+		"amount_positive":         "le montant doit être supérieur à zéro",
+		"currency_invalid":        "la devise doit être un code ISO à 3 caractères",
+		"payment_method_required": "le mode de paiement est requis",
+		"amount_below_minimum":    "le montant minimum du paiement est %.2f %s",
+		"amount_above_maximum":    "le montant maximum du paiement est %.2f %s",
+		"receipt_description":     "Paiement pour la commande %s",
+	},
+	"es": {
+		"order_id_required":       "se requiere el ID del pedido",
+		"customer_id_required":    "se requiere el ID del cliente",
+		"amount_positive":         "el monto debe ser mayor que cero",
+		"currency_invalid":        "la moneda debe ser un código ISO de 3 caracteres",
+		"payment_method_required": "se requiere el método de pago",
+		"amount_below_minimum":    "el monto mínimo de pago es %.2f %s",
+		"amount_above_maximum":    "el monto máximo de pago es %.2f %s",
+		"receipt_description":     "Pago por el pedido %s",
+	},
+}
+
+type contextKey string
+
+const languageContextKey contextKey = "language"
+
+// withLanguage attaches lang to ctx for downstream localization.
+func withLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageContextKey, lang)
+}
+
+// languageFromContext returns the language withLanguage attached to
+// ctx, or "" if none was set.
+func languageFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(languageContextKey).(string)
+	return lang
+}
+
+// languageFromHeader parses the primary language tag out of an
+// Accept-Language header value (e.g. "de-DE,de;q=0.9,en;q=0.8" -> "de"),
+// defaulting to "en" if the header is empty or unparseable.
+func languageFromHeader(header string) string {
+	if header == "" {
+		return "en"
+	}
+	primary := strings.SplitN(header, ",", 2)[0]
+	primary = strings.SplitN(primary, ";", 2)[0]
+	primary = strings.SplitN(primary, "-", 2)[0]
+	primary = strings.ToLower(strings.TrimSpace(primary))
+	if primary == "" {
+		return "en"
+	}
+	return primary
+}
+
+// PaymentServiceOption configures optional PaymentService behavior,
+// applied by NewPaymentService after its required dependencies are
+// constructed.
+type PaymentServiceOption func(*PaymentService)
+
+// WithLocalization sets the language PaymentService falls back to
+// when a request doesn't carry (or carries an unrecognized)
+// Accept-Language header. Supported out of the box: en, tr, de, fr, es.
+func WithLocalization(lang string) PaymentServiceOption {
+	return func(ps *PaymentService) {
+		ps.localizer.SetDefaultLanguage(lang)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MultiPaymentStatus is the lifecycle state of a MultiPayment.
+type MultiPaymentStatus string
+
+const (
+	MultiPaymentCreated       MultiPaymentStatus = "CREATED"
+	MultiPaymentPartiallyPaid MultiPaymentStatus = "PARTIALLY_PAID"
+	MultiPaymentCompleted     MultiPaymentStatus = "COMPLETED"
+)
+
+// MultiPayment tracks split-tender settlement of a single OrderID
+// across more than one ProcessPayment call, closing once enough of
+// them together cover Amount.
+type MultiPayment struct {
+	ID              string             `json:"id"`
+	OrderID         string             `json:"order_id"`
+	CustomerID      string             `json:"customer_id"`
+	Currency        string             `json:"currency"`
+	Amount          float64            `json:"amount"`           // Total price to collect
+	PaidPrice       float64            `json:"paid_price"`       // Sum of contributions applied so far
+	RemainingAmount float64            `json:"remaining_amount"` // Amount - PaidPrice
+	Status          MultiPaymentStatus `json:"status"`
+	TransactionIDs  []string           `json:"transaction_ids"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// MultiPaymentStore is the in-memory record of every MultiPayment,
+// keyed by ID.
+type MultiPaymentStore struct {
+	mu   sync.Mutex
+	byID map[string]*MultiPayment
+}
+
+// NewMultiPaymentStore returns an empty MultiPaymentStore.
+func NewMultiPaymentStore() *MultiPaymentStore {
+	return &MultiPaymentStore{byID: make(map[string]*MultiPayment)}
+}
+
+// Create starts a new MultiPayment for orderID/customerID to collect
+// amount in currency.
+func (s *MultiPaymentStore) Create(orderID, customerID, currency string, amount float64) *MultiPayment {
+	mp := &MultiPayment{
+		ID:              "MP_" + randomMultiPaymentID(),
+		OrderID:         orderID,
+		CustomerID:      customerID,
+		Currency:        currency,
+		Amount:          amount,
+		RemainingAmount: amount,
+		Status:          MultiPaymentCreated,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	s.mu.Lock()
+	s.byID[mp.ID] = mp
+	s.mu.Unlock()
+	return mp
+}
+
+// Get returns a copy of the MultiPayment recorded under id.
+func (s *MultiPaymentStore) Get(id string) (*MultiPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mp, ok := s.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("no multi-payment found for id %q", id)
+	}
+	copied := *mp
+	return &copied, nil
+}
+
+// ApplyContribution records a transactionID payment of amount against
+// the MultiPayment named id, rejecting it outright if it would
+// overpay the MultiPayment or if it's already COMPLETED, and reports
+// whether this contribution closed it.
+func (s *MultiPaymentStore) ApplyContribution(id, transactionID string, amount float64) (closed bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mp, ok := s.byID[id]
+	if !ok {
+		return false, fmt.Errorf("no multi-payment found for id %q", id)
+	}
+	if mp.Status == MultiPaymentCompleted {
+		return false, fmt.Errorf("multi-payment %q is already completed", id)
+	}
+	if amount > mp.RemainingAmount {
+		return false, fmt.Errorf("payment amount %.2f exceeds remaining balance %.2f for multi-payment %q", amount, mp.RemainingAmount, id)
+	}
+
+	mp.PaidPrice += amount
+	mp.RemainingAmount -= amount
+	mp.TransactionIDs = append(mp.TransactionIDs, transactionID)
+	mp.UpdatedAt = time.Now()
+	if mp.RemainingAmount <= 0 {
+		mp.Status = MultiPaymentCompleted
+		closed = true
+	} else {
+		mp.Status = MultiPaymentPartiallyPaid
+	}
+	return closed, nil
+}
+
+func randomMultiPaymentID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// CreateMultiPaymentRequest is the body of POST /api/v1/payments/multi.
+type CreateMultiPaymentRequest struct {
+	OrderID    string  `json:"order_id" validate:"required"`
+	CustomerID string  `json:"customer_id" validate:"required"`
+	Amount     float64 `json:"amount" validate:"required,min=0.01"`
+	Currency   string  `json:"currency" validate:"required,len=3"`
+}
+
+// handleCreateMultiPayment handles POST /api/v1/payments/multi,
+// opening a new split-tender MultiPayment that subsequent
+// ProcessPayment calls (naming its ID as PaymentRequest.MultiPaymentID)
+// can each pay down part of.
+func (ps *PaymentService) handleCreateMultiPayment(w http.ResponseWriter, r *http.Request) {
+	var req CreateMultiPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	if req.OrderID == "" || req.CustomerID == "" || req.Amount <= 0 || len(req.Currency) != 3 {
+		http.Error(w, "order_id, customer_id, a positive amount, and a 3-letter currency are required", http.StatusBadRequest)
+		return
+	}
+
+	mp := ps.multiPayments.Create(req.OrderID, req.CustomerID, req.Currency, req.Amount)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(mp)
+}
+
+// handleGetMultiPayment handles GET /api/v1/payments/multi/{id},
+// reporting how much of a split-tender order has been paid so far.
+func (ps *PaymentService) handleGetMultiPayment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	mp, err := ps.multiPayments.Get(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(mp)
+}
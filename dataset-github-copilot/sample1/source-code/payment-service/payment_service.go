@@ -7,15 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/big"
 	"net/http"
-	"strconv"
-	"strings"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/stripe/stripe-go/v74"
-	"github.com/stripe/stripe-go/v74/paymentintent"
 )
 
 // PaymentRequest represents a payment processing request with comprehensive validation
@@ -29,6 +25,7 @@ type PaymentRequest struct {
 	PaymentMethod string  `json:"payment_method" validate:"required"`  // Payment method identifier or token
 	Description   string  `json:"description"`                         // Payment description for customer records
 	Metadata      map[string]string `json:"metadata"`                  // Additional metadata for payment tracking
+	MultiPaymentID string `json:"multi_payment_id,omitempty"`          // If set, pays down an existing split-tender MultiPayment instead of settling OrderID outright
 }
 
 // PaymentResponse contains the result of payment processing operation
@@ -36,6 +33,7 @@ type PaymentRequest struct {
 // Includes necessary data for order fulfillment and customer communication
 type PaymentResponse struct {
 	TransactionID    string            `json:"transaction_id"`     // Unique transaction identifier
+	CustomerID       string            `json:"customer_id"`        // Customer the payment was collected from, for billing aggregation
 	Status           string            `json:"status"`             // Payment status (success, failed, pending)
 	Amount           float64           `json:"amount"`             // Processed payment amount
 	Currency         string            `json:"currency"`           // Currency used for payment
@@ -45,6 +43,9 @@ type PaymentResponse struct {
 	FraudScore       float64           `json:"fraud_score"`        // Risk assessment score (0-100)
 	AuthorizationCode string           `json:"authorization_code"` // Payment authorization code
 	Fees             float64           `json:"fees"`               // Processing fees charged
+	Provider         string            `json:"provider"`           // Name of the PaymentProvider that handled this transaction
+	ProviderIntentID string            `json:"provider_intent_id"` // The provider's own intent/charge ID, used to route refunds back to it
+	MultiPaymentID   string            `json:"multi_payment_id,omitempty"` // Set when this payment was a contribution toward a split-tender MultiPayment
 }
 
 // RefundRequest represents a refund processing request with validation
@@ -61,38 +62,77 @@ type RefundRequest struct {
 // Implements PCI DSS compliant payment processing with multiple gateway support
 // Provides comprehensive fraud detection and risk management capabilities
 type PaymentService struct {
-	stripeClient   *stripe.Client    // Stripe payment gateway client
-	fraudDetector  *FraudDetector    // Fraud detection service
-	auditLogger    *AuditLogger      // Audit logging service
-	encryptionKey  []byte           // Encryption key for sensitive data
-	rateLimit      *RateLimiter     // Rate limiting for payment requests
+	registry      *ProviderRegistry // Every PaymentProvider this service can route a transaction through
+	router        *ProviderRouter   // Decides which registered provider handles (and backs up) a given request
+	fraudDetector *FraudDetector    // Fraud detection service
+	auditLogger   *AuditLogger      // Audit logging service
+	encryptionKey []byte            // Encryption key for sensitive data
+	rateLimit     *RateLimiter      // Rate limiting for payment requests
+	store         *TransactionStore // In-memory record of payments and refunds, for lookups and audit
+	multiPayments *MultiPaymentStore // Split-tender orders being settled across more than one ProcessPayment call
+	threeDS       *threeDSStore     // Payments parked on a 3-D Secure challenge between InitiatePayment and ConfirmPayment
+	webhookSecret string             // Stripe signing secret handleStripeWebhook verifies Stripe-Signature against; set with SetWebhookSecret
+	webhookEvents *webhookEventStore // Stripe event IDs already reconciled, so retried deliveries are a no-op
+	localizer     *Localizer         // Message catalog behind validation errors, http.Error responses, and receipt descriptions
 }
 
 // NewPaymentService creates a new payment service instance with required dependencies
 // Initializes all necessary components for secure payment processing
 // Configures fraud detection, audit logging, and encryption services
-func NewPaymentService(stripeSecretKey string, encryptionKey []byte) *PaymentService {
-	// Initialize Stripe client with secret key
-	stripe.Key = stripeSecretKey
-	
+//
+// It registers the Stripe and ACH providers by default, routing bank
+// transfers to ACH and everything else to Stripe; call Registry() and
+// Router() on the result to register additional providers (e.g. a
+// wallet provider) or add routing rules before serving traffic.
+// Defaults to English messages; pass WithLocalization to change the
+// fallback language used when a request's Accept-Language header is
+// missing or unrecognized.
+func NewPaymentService(stripeSecretKey string, encryptionKey []byte, opts ...PaymentServiceOption) *PaymentService {
+	registry := NewProviderRegistry()
+	registry.Register(NewStripeProvider(stripeSecretKey))
+	registry.Register(NewACHProvider())
+
+	router := NewProviderRouter(registry, "stripe").
+		WithPaymentMethodRoute("bank_transfer", "ach")
+
 	// Create fraud detection service with machine learning models
 	fraudDetector := NewFraudDetector()
-	
+
 	// Initialize audit logger for compliance requirements
 	auditLogger := NewAuditLogger()
-	
+
 	// Configure rate limiter to prevent abuse
 	rateLimit := NewRateLimiter(100, time.Minute) // 100 requests per minute
-	
-	return &PaymentService{
-		stripeClient:  &stripe.Client{},
+
+	ps := &PaymentService{
+		registry:      registry,
+		router:        router,
 		fraudDetector: fraudDetector,
 		auditLogger:   auditLogger,
 		encryptionKey: encryptionKey,
 		rateLimit:     rateLimit,
+		store:         NewTransactionStore(),
+		multiPayments: NewMultiPaymentStore(),
+		threeDS:       newThreeDSStore(),
+		webhookEvents: newWebhookEventStore(),
+		localizer:     NewLocalizer("en"),
+	}
+	for _, opt := range opts {
+		opt(ps)
 	}
+	return ps
 }
 
+// Registry returns the provider registry backing this service, so
+// callers can register additional providers (e.g. a wallet provider)
+// before serving traffic.
+func (ps *PaymentService) Registry() *ProviderRegistry { return ps.registry }
+
+// Router returns the routing rules backing this service, so callers
+// can add currency, payment method, large-amount, or per-customer
+// routes before serving traffic.
+func (ps *PaymentService) Router() *ProviderRouter { return ps.router }
+
 // ProcessPayment handles payment authorization and capture with comprehensive validation
 // Implements fraud detection, risk assessment, and compliance requirements
 // Supports multiple payment gateways with intelligent routing based on transaction characteristics
@@ -106,11 +146,33 @@ func (ps *PaymentService) ProcessPayment(ctx context.Context, req PaymentRequest
 	}
 	
 	// Validate payment request data against business rules and compliance requirements
-	if err := ps.validatePaymentRequest(req); err != nil {
+	if err := ps.validatePaymentRequest(ctx, req); err != nil {
 		ps.auditLogger.LogValidationError("PAYMENT_VALIDATION_FAILED", req.CustomerID, err.Error())
 		return nil, fmt.Errorf("payment validation failed: %w", err)
 	}
-	
+	if req.Description == "" {
+		req.Description = ps.localizer.Message(languageFromContext(ctx), "receipt_description", req.OrderID)
+	}
+
+	// If this payment is a contribution toward a split-tender
+	// MultiPayment, confirm it exists, matches this order, and isn't
+	// already settled or about to be overpaid before we ever reach the
+	// gateway.
+	var multiPayment *MultiPayment
+	if req.MultiPaymentID != "" {
+		mp, err := ps.multiPayments.Get(req.MultiPaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("multi-payment lookup failed: %w", err)
+		}
+		if mp.OrderID != req.OrderID {
+			return nil, fmt.Errorf("order %q does not match multi-payment %q", req.OrderID, req.MultiPaymentID)
+		}
+		if req.Amount > mp.RemainingAmount {
+			return nil, fmt.Errorf("payment amount %.2f exceeds remaining balance %.2f for multi-payment %q", req.Amount, mp.RemainingAmount, req.MultiPaymentID)
+		}
+		multiPayment = mp
+	}
+
 	// Perform comprehensive fraud detection and risk assessment
 	fraudScore, riskFactors := ps.fraudDetector.AssessRisk(req)
 	log.Printf("Fraud assessment completed - Score: %.2f, Risk factors: %v", fraudScore, riskFactors)
@@ -123,28 +185,88 @@ func (ps *PaymentService) ProcessPayment(ctx context.Context, req PaymentRequest
 	
 	// Generate unique transaction identifier for tracking
 	transactionID := ps.generateTransactionID()
-	
-	// Create payment intent with Stripe gateway
-	paymentIntent, err := ps.createStripePaymentIntent(req, transactionID)
+
+	providerName, confirmedIntent, err := ps.authorizeWithFailover(ctx, req, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ps.finalizePayment(req, transactionID, providerName, confirmedIntent, fraudScore, multiPayment)
+
+	// Log successful payment processing for audit trail
+	ps.auditLogger.LogPaymentSuccess(transactionID, req.CustomerID, req.Amount)
+
+	log.Printf("Payment processed successfully - Transaction ID: %s", transactionID)
+	return response, nil
+}
+
+// authorizeWithFailover routes req to a provider based on currency,
+// amount, payment method, or per-customer preference, and fails over
+// to a secondary provider if the primary's gateway call errors out.
+// It returns whichever provider name actually handled the request
+// alongside the confirmed intent; the intent may still report
+// RequiresAction (e.g. a 3-D Secure challenge) rather than a terminal
+// status.
+func (ps *PaymentService) authorizeWithFailover(ctx context.Context, req PaymentRequest, transactionID string) (string, *ProviderIntent, error) {
+	providerName, provider, err := ps.router.Select(req)
+	if err != nil {
+		ps.auditLogger.LogPaymentError("NO_PROVIDER_AVAILABLE", req.CustomerID, err.Error())
+		return "", nil, fmt.Errorf("no payment provider available: %w", err)
+	}
+
+	confirmedIntent, err := ps.createAndConfirmIntent(ctx, provider, req, transactionID)
+	if err != nil {
+		fallbackName, fallbackProvider, fbErr := ps.router.SelectFallback(req, providerName)
+		if fbErr != nil {
+			ps.auditLogger.LogPaymentError("PROVIDER_FAILED_NO_FALLBACK", req.CustomerID, err.Error())
+			return "", nil, fmt.Errorf("payment failed on provider %q and no fallback is available: %w", providerName, err)
+		}
+
+		log.Printf("Provider %q failed (%v), failing over to %q", providerName, err, fallbackName)
+		ps.auditLogger.LogPaymentError("PROVIDER_FAILOVER", req.CustomerID, fmt.Sprintf("%s -> %s: %v", providerName, fallbackName, err))
+
+		confirmedIntent, err = ps.createAndConfirmIntent(ctx, fallbackProvider, req, transactionID)
+		if err != nil {
+			ps.auditLogger.LogPaymentError("PROVIDER_FAILOVER_FAILED", req.CustomerID, err.Error())
+			return "", nil, fmt.Errorf("payment failed on primary provider %q and fallback provider %q: %w", providerName, fallbackName, err)
+		}
+		providerName = fallbackName
+	}
+
+	return providerName, confirmedIntent, nil
+}
+
+// createAndConfirmIntent opens a new intent for req with provider and
+// immediately confirms it, the two-call sequence every PaymentProvider
+// implements to authorize and capture a payment.
+func (ps *PaymentService) createAndConfirmIntent(ctx context.Context, provider PaymentProvider, req PaymentRequest, transactionID string) (*ProviderIntent, error) {
+	intent, err := provider.CreateIntent(ctx, req, transactionID)
 	if err != nil {
-		ps.auditLogger.LogPaymentError("STRIPE_PAYMENT_INTENT_FAILED", req.CustomerID, err.Error())
 		return nil, fmt.Errorf("failed to create payment intent: %w", err)
 	}
-	
-	// Confirm payment intent to authorize and capture funds
-	confirmedIntent, err := ps.confirmPaymentIntent(paymentIntent.ID, req.PaymentMethod)
+	confirmed, err := provider.Confirm(ctx, intent.ID, req.PaymentMethod)
 	if err != nil {
-		ps.auditLogger.LogPaymentError("PAYMENT_CONFIRMATION_FAILED", req.CustomerID, err.Error())
 		return nil, fmt.Errorf("payment confirmation failed: %w", err)
 	}
-	
+	return confirmed, nil
+}
+
+// finalizePayment builds the PaymentResponse for confirmedIntent
+// (whose status may still be non-terminal, e.g. PENDING for an ACH
+// transfer), stores it for audit/refund lookups, and publishes
+// the PAYMENT_SUCCESSFUL (or, for an unfinished MultiPayment,
+// PAYMENT_PARTIALLY_PAID) event. It's shared by ProcessPayment and by
+// ConfirmPayment completing a payment InitiatePayment left pending on
+// a 3-D Secure challenge.
+func (ps *PaymentService) finalizePayment(req PaymentRequest, transactionID, providerName string, confirmedIntent *ProviderIntent, fraudScore float64, multiPayment *MultiPayment) *PaymentResponse {
 	// Calculate processing fees based on payment method and amount
 	processingFees := ps.calculateProcessingFees(req.Amount, req.PaymentMethod)
-	
+
 	// Build comprehensive payment response with all transaction details
 	response := &PaymentResponse{
 		TransactionID:     transactionID,
-		Status:           string(confirmedIntent.Status),
+		CustomerID:       req.CustomerID,
+		Status:           confirmedIntent.Status,
 		Amount:           req.Amount,
 		Currency:         req.Currency,
 		ProcessedAt:      time.Now(),
@@ -153,21 +275,35 @@ func (ps *PaymentService) ProcessPayment(ctx context.Context, req PaymentRequest
 		FraudScore:       fraudScore,
 		AuthorizationCode: ps.extractAuthorizationCode(confirmedIntent),
 		Fees:             processingFees,
+		Provider:         providerName,
+		ProviderIntentID: confirmedIntent.ID,
 	}
-	
+
 	// Store payment transaction for audit and compliance
 	if err := ps.storePaymentTransaction(req, response); err != nil {
 		log.Printf("Warning: Failed to store payment transaction: %v", err)
 	}
-	
-	// Publish payment success event for other microservices
-	ps.publishPaymentEvent("PAYMENT_SUCCESSFUL", response)
-	
-	// Log successful payment processing for audit trail
-	ps.auditLogger.LogPaymentSuccess(transactionID, req.CustomerID, req.Amount)
-	
-	log.Printf("Payment processed successfully - Transaction ID: %s", transactionID)
-	return response, nil
+
+	if multiPayment != nil {
+		response.MultiPaymentID = multiPayment.ID
+		closed, err := ps.multiPayments.ApplyContribution(multiPayment.ID, transactionID, req.Amount)
+		if err != nil {
+			log.Printf("Warning: Failed to apply contribution to multi-payment %s: %v", multiPayment.ID, err)
+		}
+		// A split-tender order only counts as paid once enough
+		// contributions close it out; partial contributions get their
+		// own event so listeners don't fulfill the order early.
+		if closed {
+			ps.publishPaymentEvent("PAYMENT_SUCCESSFUL", response)
+		} else {
+			ps.publishPaymentEvent("PAYMENT_PARTIALLY_PAID", response)
+		}
+	} else {
+		// Publish payment success event for other microservices
+		ps.publishPaymentEvent("PAYMENT_SUCCESSFUL", response)
+	}
+
+	return response
 }
 
 // ProcessRefund handles refund operations with proper validation and audit trail
@@ -200,43 +336,36 @@ func (ps *PaymentService) ProcessRefund(ctx context.Context, req RefundRequest)
 		return nil, fmt.Errorf("refund not allowed for transaction: %s", req.TransactionID)
 	}
 	
-	// Convert amount to cents for Stripe API (Stripe uses smallest currency unit)
-	refundAmountCents := int64(req.Amount * 100)
-	
-	// Create refund with Stripe gateway
-	refundParams := &stripe.RefundParams{
-		PaymentIntent: stripe.String(originalPayment.TransactionID),
-		Amount:        stripe.Int64(refundAmountCents),
-		Reason:        stripe.String(req.Reason),
-		Metadata: map[string]string{
-			"requested_by": req.RequestedBy,
-			"refund_reason": req.Reason,
-			"original_order": originalPayment.OrderID,
-		},
+	// Route the refund back to whichever provider originally authorized
+	// the payment; a refund always has to land on the same gateway the
+	// charge was made through.
+	provider, err := ps.registry.Get(originalPayment.Provider)
+	if err != nil {
+		ps.auditLogger.LogPaymentError("REFUND_PROVIDER_NOT_FOUND", req.RequestedBy, err.Error())
+		return nil, fmt.Errorf("refund failed: %w", err)
 	}
-	
-	refund, err := ps.stripeClient.Refunds.New(refundParams)
+
+	refund, err := provider.Refund(ctx, originalPayment.ProviderIntentID, req.Amount, req.Reason)
 	if err != nil {
-		ps.auditLogger.LogPaymentError("STRIPE_REFUND_FAILED", req.RequestedBy, err.Error())
-		return nil, fmt.Errorf("failed to process refund with Stripe: %w", err)
+		ps.auditLogger.LogPaymentError("PROVIDER_REFUND_FAILED", req.RequestedBy, err.Error())
+		return nil, fmt.Errorf("failed to process refund with %s: %w", originalPayment.Provider, err)
 	}
-	
+
 	// Generate unique refund transaction ID
 	refundTransactionID := ps.generateTransactionID()
-	
+
 	// Build refund response with transaction details
 	response := &PaymentResponse{
-		TransactionID:   refundTransactionID,
-		Status:         string(refund.Status),
-		Amount:         req.Amount,
-		Currency:       originalPayment.Currency,
-		ProcessedAt:    time.Now(),
-		PaymentMethodID: originalPayment.PaymentMethodID,
-		GatewayResponse: map[string]interface{}{
-			"refund_id": refund.ID,
-			"reason":    refund.Reason,
-		},
-		Fees: ps.calculateRefundFees(req.Amount),
+		TransactionID:    refundTransactionID,
+		Status:           refund.Status,
+		Amount:           req.Amount,
+		Currency:         originalPayment.Currency,
+		ProcessedAt:      time.Now(),
+		PaymentMethodID:  originalPayment.PaymentMethodID,
+		GatewayResponse:  refund.Raw,
+		Fees:             ps.calculateRefundFees(req.Amount),
+		Provider:         originalPayment.Provider,
+		ProviderIntentID: refund.ID,
 	}
 	
 	// Store refund transaction for audit and compliance
@@ -265,9 +394,10 @@ func (ps *PaymentService) handleProcessPayment(w http.ResponseWriter, r *http.Re
 	}
 	
 	// Process payment with context timeout
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	ctx := withLanguage(r.Context(), languageFromHeader(r.Header.Get("Accept-Language")))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	
+
 	response, err := ps.ProcessPayment(ctx, req)
 	if err != nil {
 		log.Printf("Payment processing failed: %v", err)
@@ -290,9 +420,10 @@ func (ps *PaymentService) handleProcessRefund(w http.ResponseWriter, r *http.Req
 	}
 	
 	// Process refund with timeout
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	ctx := withLanguage(r.Context(), languageFromHeader(r.Header.Get("Accept-Language")))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	
+
 	response, err := ps.ProcessRefund(ctx, req)
 	if err != nil {
 		log.Printf("Refund processing failed: %v", err)
@@ -307,32 +438,34 @@ func (ps *PaymentService) handleProcessRefund(w http.ResponseWriter, r *http.Req
 
 // Private helper methods for payment processing
 
-func (ps *PaymentService) validatePaymentRequest(req PaymentRequest) error {
+func (ps *PaymentService) validatePaymentRequest(ctx context.Context, req PaymentRequest) error {
+	lang := languageFromContext(ctx)
+
 	// Validate required fields are present
 	if req.OrderID == "" {
-		return fmt.Errorf("order ID is required")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "order_id_required"))
 	}
 	if req.CustomerID == "" {
-		return fmt.Errorf("customer ID is required")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "customer_id_required"))
 	}
 	if req.Amount <= 0 {
-		return fmt.Errorf("amount must be greater than zero")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "amount_positive"))
 	}
 	if len(req.Currency) != 3 {
-		return fmt.Errorf("currency must be 3 character ISO code")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "currency_invalid"))
 	}
 	if req.PaymentMethod == "" {
-		return fmt.Errorf("payment method is required")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "payment_method_required"))
 	}
-	
+
 	// Validate amount limits
 	if req.Amount < 0.50 {
-		return fmt.Errorf("minimum payment amount is $0.50")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "amount_below_minimum", 0.50, req.Currency))
 	}
 	if req.Amount > 50000.00 {
-		return fmt.Errorf("maximum payment amount is $50,000.00")
+		return fmt.Errorf("%s", ps.localizer.Message(lang, "amount_above_maximum", 50000.00, req.Currency))
 	}
-	
+
 	return nil
 }
 
@@ -346,38 +479,6 @@ func (ps *PaymentService) generateTransactionID() string {
 	return "TXN_" + hex.EncodeToString(bytes)
 }
 
-func (ps *PaymentService) createStripePaymentIntent(req PaymentRequest, transactionID string) (*stripe.PaymentIntent, error) {
-	// Convert amount to cents (Stripe uses smallest currency unit)
-	amountCents := int64(req.Amount * 100)
-	
-	// Create payment intent parameters
-	params := &stripe.PaymentIntentParams{
-		Amount:   stripe.Int64(amountCents),
-		Currency: stripe.String(strings.ToLower(req.Currency)),
-		PaymentMethod: stripe.String(req.PaymentMethod),
-		ConfirmationMethod: stripe.String("manual"),
-		Confirm: stripe.Bool(false),
-		Description: stripe.String(req.Description),
-		Metadata: map[string]string{
-			"order_id": req.OrderID,
-			"customer_id": req.CustomerID,
-			"transaction_id": transactionID,
-		},
-	}
-	
-	// Create payment intent with Stripe
-	return paymentintent.New(params)
-}
-
-func (ps *PaymentService) confirmPaymentIntent(paymentIntentID, paymentMethodID string) (*stripe.PaymentIntent, error) {
-	// Confirm payment intent to process payment
-	params := &stripe.PaymentIntentConfirmParams{
-		PaymentMethod: stripe.String(paymentMethodID),
-	}
-	
-	return paymentintent.Confirm(paymentIntentID, params)
-}
-
 func (ps *PaymentService) calculateProcessingFees(amount float64, paymentMethod string) float64 {
 	// Calculate processing fees based on payment method
 	var feeRate float64
@@ -426,27 +527,108 @@ type RateLimiter struct{}
 func NewRateLimiter(limit int, window time.Duration) *RateLimiter { return &RateLimiter{} }
 func (rl *RateLimiter) Allow(key string) bool { return true }
 
-// Additional helper methods would be implemented for:
-// - validateRefundRequest()
-// - getOriginalPaymentTransaction()
-// - isRefundAllowed()
-// - storePaymentTransaction()
-// - storeRefundTransaction()
-// - publishPaymentEvent()
-// - buildGatewayResponse()
-// - extractAuthorizationCode()
-// - calculateRefundFees()
+// validateRefundRequest, getOriginalPaymentTransaction,
+// isRefundAllowed, storePaymentTransaction, storeRefundTransaction,
+// publishPaymentEvent, buildGatewayResponse, extractAuthorizationCode,
+// and calculateRefundFees are implemented in store.go.
+
+// runBillingCLI implements the prepare-invoice-records,
+// create-invoice-items, and create-invoices subcommands for ops to
+// drive billing by hand (e.g. from a monthly cron job) instead of
+// through the HTTP API.
+func runBillingCLI(bs *BillingService, args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: payment-service <prepare-invoice-records <period>|create-invoice-items <record_id>|create-invoices <record_id>>")
+	}
+
+	switch args[0] {
+	case "prepare-invoice-records":
+		if len(args) < 2 {
+			log.Fatal("usage: payment-service prepare-invoice-records <period YYYY-MM>")
+		}
+		records, err := bs.PrepareInvoiceRecords(args[1], "USD")
+		if err != nil {
+			log.Fatalf("prepare-invoice-records failed: %v", err)
+		}
+		output, _ := json.MarshalIndent(records, "", "  ")
+		fmt.Println(string(output))
+	case "create-invoice-items":
+		if len(args) < 2 {
+			log.Fatal("usage: payment-service create-invoice-items <record_id>")
+		}
+		item, err := bs.CreateInvoiceItems(args[1])
+		if err != nil {
+			log.Fatalf("create-invoice-items failed: %v", err)
+		}
+		output, _ := json.MarshalIndent(item, "", "  ")
+		fmt.Println(string(output))
+	case "create-invoices":
+		if len(args) < 2 {
+			log.Fatal("usage: payment-service create-invoices <record_id>")
+		}
+		inv, err := bs.CreateInvoices(args[1])
+		if err != nil {
+			log.Fatalf("create-invoices failed: %v", err)
+		}
+		output, _ := json.MarshalIndent(inv, "", "  ")
+		fmt.Println(string(output))
+	default:
+		log.Fatalf("unknown billing command %q", args[0])
+	}
+}
 
 func main() {
 	// Initialize payment service
 	encryptionKey := make([]byte, 32) // In production, load from secure configuration
-	ps := NewPaymentService("sk_test_stripe_key", encryptionKey)
-	
+	ps := NewPaymentService("sk_test_stripe_key", encryptionKey, WithLocalization("en"))
+	ps.SetWebhookSecret("whsec_test_webhook_secret")
+
+	// Billing aggregates succeeded payments into monthly Stripe
+	// invoices; its FXConverter needs to be running before any
+	// multi-currency period is prepared.
+	fx := NewFXConverter("USD", time.Hour)
+	fxCtx, stopFX := context.WithCancel(context.Background())
+	defer stopFX()
+	go func() {
+		if err := fx.Run(fxCtx); err != nil && err != context.Canceled {
+			log.Printf("FX rate refresh loop stopped: %v", err)
+		}
+	}()
+	defer fx.Close()
+
+	bs := NewBillingService(ps.store, fx)
+
+	if len(os.Args) > 1 {
+		runBillingCLI(bs, os.Args[1:])
+		return
+	}
+
+	// Wallet deposits settle through the same FXConverter billing
+	// uses, so customers can pay in crypto without a separate
+	// conversion path.
+	ws := NewWalletService(ps, fx, "USD", "https://rpc.example.invalid", 30*time.Second)
+	go func() {
+		if err := ws.Run(fxCtx); err != nil && err != context.Canceled {
+			log.Printf("Wallet deposit scanner stopped: %v", err)
+		}
+	}()
+	defer ws.Close()
+
 	// Set up HTTP routes
 	r := mux.NewRouter()
 	r.HandleFunc("/api/v1/payments/process", ps.handleProcessPayment).Methods("POST")
 	r.HandleFunc("/api/v1/payments/refund", ps.handleProcessRefund).Methods("POST")
-	
+	r.HandleFunc("/api/v1/payments/multi", ps.handleCreateMultiPayment).Methods("POST")
+	r.HandleFunc("/api/v1/payments/multi/{id}", ps.handleGetMultiPayment).Methods("GET")
+	r.HandleFunc("/api/v1/payments/initiate", ps.handleInitiatePayment).Methods("POST")
+	r.HandleFunc("/api/v1/payments/confirm/{id}", ps.handleConfirmPayment).Methods("POST")
+	r.HandleFunc("/api/v1/payments/webhook/stripe", ps.handleStripeWebhook).Methods("POST")
+	r.HandleFunc("/api/v1/billing/prepare-invoice-records", bs.handlePrepareInvoiceRecords).Methods("POST")
+	r.HandleFunc("/api/v1/billing/create-invoice-items", bs.handleCreateInvoiceItems).Methods("POST")
+	r.HandleFunc("/api/v1/billing/create-invoices", bs.handleCreateInvoices).Methods("POST")
+	r.HandleFunc("/api/v1/wallets/claim", ws.handleClaimWallet).Methods("POST")
+	r.HandleFunc("/api/v1/wallets/payments", ws.handleListWalletPayments).Methods("GET")
+
 	// Start HTTP server
 	log.Println("Payment service starting on port 8082")
 	log.Fatal(http.ListenAndServe(":8082", r))
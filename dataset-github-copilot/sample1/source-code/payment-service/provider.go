@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stripe/stripe-go/v74"
+	"github.com/stripe/stripe-go/v74/paymentintent"
+)
+
+// ProviderIntent is the gateway-agnostic result of CreateIntent and
+// Confirm. PaymentProvider implementations translate their own SDK
+// types into this shape so ProcessPayment never has to know which
+// gateway actually handled a request.
+type ProviderIntent struct {
+	ID             string                 // Gateway-assigned identifier for the intent/charge
+	Status         string                 // Gateway status, normalized to uppercase (e.g. "SUCCEEDED", "PENDING")
+	RequiresAction bool                   // True when the gateway needs an extra customer step (e.g. 3DS) before it can settle
+	ClientSecret   string                 // Opaque value a client-side SDK needs to complete a RequiresAction step, if any
+	Raw            map[string]interface{} // The gateway's own response fields, for GatewayResponse and debugging
+}
+
+// PaymentProvider is implemented by each payment gateway the service
+// can route a transaction through. A provider owns everything gateway
+// specific; ProcessPayment and ProcessRefund only ever talk to this
+// interface, so adding a gateway never touches the routing or fraud
+// logic around it.
+type PaymentProvider interface {
+	// Name identifies this provider in routing decisions, audit logs,
+	// and PaymentResponse.Provider.
+	Name() string
+	// CreateIntent opens a new payment intent for req without moving
+	// money yet.
+	CreateIntent(ctx context.Context, req PaymentRequest, transactionID string) (*ProviderIntent, error)
+	// Confirm authorizes and captures intentID against paymentMethod.
+	Confirm(ctx context.Context, intentID, paymentMethod string) (*ProviderIntent, error)
+	// Refund returns amount to the customer for a previously confirmed
+	// intentID.
+	Refund(ctx context.Context, intentID string, amount float64, reason string) (*ProviderIntent, error)
+	// RetrievePayment looks up the current state of intentID.
+	RetrievePayment(ctx context.Context, intentID string) (*ProviderIntent, error)
+}
+
+// ProviderRegistry holds every PaymentProvider the service knows
+// about, keyed by the name ProcessPayment's routing decision selects.
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]PaymentProvider
+}
+
+// NewProviderRegistry returns an empty registry. Register providers
+// with Register before routing any payment through it.
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{providers: make(map[string]PaymentProvider)}
+}
+
+// Register adds provider under its own Name(), overwriting any
+// previous provider registered under that name.
+func (r *ProviderRegistry) Register(provider PaymentProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Name()] = provider
+}
+
+// Get looks up the provider registered under name.
+func (r *ProviderRegistry) Get(name string) (PaymentProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("no payment provider registered for %q", name)
+	}
+	return provider, nil
+}
+
+// ProviderRouter decides which registered provider handles a given
+// PaymentRequest, and which one to fail over to if that provider's
+// gateway call errors out.
+type ProviderRouter struct {
+	registry *ProviderRegistry
+
+	// defaultProvider handles any request that doesn't match a more
+	// specific rule below.
+	defaultProvider string
+	// currencyProviders overrides defaultProvider for requests in a
+	// given ISO 4217 currency (e.g. routing EUR through a
+	// SEPA-friendly gateway).
+	currencyProviders map[string]string
+	// paymentMethodProviders overrides defaultProvider (and any
+	// currency match) for requests naming a specific PaymentMethod
+	// family, such as "bank_transfer" or "wallet:...".
+	paymentMethodProviders map[string]string
+	// largeAmountThreshold and largeAmountProvider route
+	// high-value transactions to a provider with better large-ticket
+	// terms, regardless of currency or payment method.
+	largeAmountThreshold float64
+	largeAmountProvider  string
+
+	mu          sync.RWMutex
+	preferences map[string]string // customerID -> preferred provider name
+}
+
+// NewProviderRouter returns a router that sends everything to
+// defaultProvider until rules are added with WithCurrencyRoute,
+// WithPaymentMethodRoute, or WithLargeAmountRoute.
+func NewProviderRouter(registry *ProviderRegistry, defaultProvider string) *ProviderRouter {
+	return &ProviderRouter{
+		registry:                registry,
+		defaultProvider:         defaultProvider,
+		currencyProviders:       make(map[string]string),
+		paymentMethodProviders:  make(map[string]string),
+		preferences:             make(map[string]string),
+	}
+}
+
+// WithCurrencyRoute routes requests in currency (ISO 4217) to
+// providerName.
+func (r *ProviderRouter) WithCurrencyRoute(currency, providerName string) *ProviderRouter {
+	r.currencyProviders[strings.ToUpper(currency)] = providerName
+	return r
+}
+
+// WithPaymentMethodRoute routes requests whose PaymentMethod has
+// prefix to providerName (e.g. prefix "bank_transfer" or "wallet:").
+func (r *ProviderRouter) WithPaymentMethodRoute(prefix, providerName string) *ProviderRouter {
+	r.paymentMethodProviders[prefix] = providerName
+	return r
+}
+
+// WithLargeAmountRoute routes any request at or above threshold to
+// providerName, taking priority over currency and payment method
+// rules.
+func (r *ProviderRouter) WithLargeAmountRoute(threshold float64, providerName string) *ProviderRouter {
+	r.largeAmountThreshold = threshold
+	r.largeAmountProvider = providerName
+	return r
+}
+
+// SetCustomerPreference pins customerID's payments to providerName
+// ahead of every other routing rule, until cleared by passing an empty
+// providerName.
+func (r *ProviderRouter) SetCustomerPreference(customerID, providerName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if providerName == "" {
+		delete(r.preferences, customerID)
+		return
+	}
+	r.preferences[customerID] = providerName
+}
+
+// Select resolves req to the provider it should route through, in
+// priority order: per-customer preference, large-amount threshold,
+// payment method prefix, currency, then the router's default.
+func (r *ProviderRouter) Select(req PaymentRequest) (string, PaymentProvider, error) {
+	return r.resolve(r.selectName(req))
+}
+
+// SelectFallback resolves req to the secondary provider ProcessPayment
+// should retry against after excludeName's gateway call failed. It
+// reuses the same priority rules as Select but skips any rule that
+// would resolve back to excludeName, falling back to any other
+// registered provider if every matching rule points at excludeName.
+func (r *ProviderRouter) SelectFallback(req PaymentRequest, excludeName string) (string, PaymentProvider, error) {
+	for _, name := range r.candidateNames(req) {
+		if name == excludeName || name == "" {
+			continue
+		}
+		if provider, err := r.registry.Get(name); err == nil {
+			return name, provider, nil
+		}
+	}
+	r.registry.mu.RLock()
+	defer r.registry.mu.RUnlock()
+	others := make([]string, 0, len(r.registry.providers))
+	for name := range r.registry.providers {
+		if name != excludeName {
+			others = append(others, name)
+		}
+	}
+	if len(others) > 0 {
+		sort.Strings(others)
+		return others[0], r.registry.providers[others[0]], nil
+	}
+	return "", nil, fmt.Errorf("no fallback payment provider available for customer %q (primary %q failed)", req.CustomerID, excludeName)
+}
+
+func (r *ProviderRouter) resolve(name string) (string, PaymentProvider, error) {
+	provider, err := r.registry.Get(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, provider, nil
+}
+
+// selectName is Select's routing decision without the registry
+// lookup, reused by candidateNames to build the fallback order.
+func (r *ProviderRouter) selectName(req PaymentRequest) string {
+	r.mu.RLock()
+	if preferred, ok := r.preferences[req.CustomerID]; ok {
+		r.mu.RUnlock()
+		return preferred
+	}
+	r.mu.RUnlock()
+
+	if r.largeAmountProvider != "" && req.Amount >= r.largeAmountThreshold {
+		return r.largeAmountProvider
+	}
+	for prefix, name := range r.paymentMethodProviders {
+		if strings.HasPrefix(req.PaymentMethod, prefix) {
+			return name
+		}
+	}
+	if name, ok := r.currencyProviders[strings.ToUpper(req.Currency)]; ok {
+		return name
+	}
+	return r.defaultProvider
+}
+
+// candidateNames lists every provider name a rule could select for
+// req, most to least specific, for SelectFallback to walk past
+// whichever one already failed.
+func (r *ProviderRouter) candidateNames(req PaymentRequest) []string {
+	names := []string{r.selectName(req)}
+	for prefix, name := range r.paymentMethodProviders {
+		if strings.HasPrefix(req.PaymentMethod, prefix) {
+			names = append(names, name)
+		}
+	}
+	if name, ok := r.currencyProviders[strings.ToUpper(req.Currency)]; ok {
+		names = append(names, name)
+	}
+	names = append(names, r.defaultProvider)
+	return names
+}
+
+// stripeProvider implements PaymentProvider against the real Stripe
+// PaymentIntents API, the gateway this service has always used.
+type stripeProvider struct {
+	secretKey string
+}
+
+// NewStripeProvider configures the Stripe SDK with secretKey and
+// returns a PaymentProvider backed by it.
+func NewStripeProvider(secretKey string) PaymentProvider {
+	stripe.Key = secretKey
+	return &stripeProvider{secretKey: secretKey}
+}
+
+func (p *stripeProvider) Name() string { return "stripe" }
+
+func (p *stripeProvider) CreateIntent(ctx context.Context, req PaymentRequest, transactionID string) (*ProviderIntent, error) {
+	// Convert amount to cents (Stripe uses the smallest currency unit).
+	amountCents := int64(req.Amount * 100)
+
+	params := &stripe.PaymentIntentParams{
+		Amount:             stripe.Int64(amountCents),
+		Currency:           stripe.String(strings.ToLower(req.Currency)),
+		PaymentMethod:      stripe.String(req.PaymentMethod),
+		ConfirmationMethod: stripe.String("manual"),
+		Confirm:            stripe.Bool(false),
+		Description:        stripe.String(req.Description),
+		Metadata: map[string]string{
+			"order_id":       req.OrderID,
+			"customer_id":    req.CustomerID,
+			"transaction_id": transactionID,
+		},
+	}
+
+	intent, err := paymentintent.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return stripeIntentToProviderIntent(intent), nil
+}
+
+func (p *stripeProvider) Confirm(ctx context.Context, intentID, paymentMethod string) (*ProviderIntent, error) {
+	params := &stripe.PaymentIntentConfirmParams{
+		PaymentMethod: stripe.String(paymentMethod),
+	}
+	intent, err := paymentintent.Confirm(intentID, params)
+	if err != nil {
+		return nil, err
+	}
+	return stripeIntentToProviderIntent(intent), nil
+}
+
+func (p *stripeProvider) Refund(ctx context.Context, intentID string, amount float64, reason string) (*ProviderIntent, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(intentID),
+		Amount:        stripe.Int64(int64(amount * 100)),
+		Reason:        stripe.String(reason),
+	}
+	client := &stripe.Client{}
+	refund, err := client.Refunds.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderIntent{
+		ID:     refund.ID,
+		Status: strings.ToUpper(string(refund.Status)),
+		Raw: map[string]interface{}{
+			"refund_id": refund.ID,
+			"reason":    refund.Reason,
+		},
+	}, nil
+}
+
+func (p *stripeProvider) RetrievePayment(ctx context.Context, intentID string) (*ProviderIntent, error) {
+	intent, err := paymentintent.Get(intentID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return stripeIntentToProviderIntent(intent), nil
+}
+
+// stripeIntentToProviderIntent normalizes a Stripe PaymentIntent into
+// the gateway-agnostic ProviderIntent shape.
+func stripeIntentToProviderIntent(intent *stripe.PaymentIntent) *ProviderIntent {
+	return &ProviderIntent{
+		ID:             intent.ID,
+		Status:         strings.ToUpper(string(intent.Status)),
+		RequiresAction: intent.Status == stripe.PaymentIntentStatusRequiresAction,
+		ClientSecret:   intent.ClientSecret,
+		Raw: map[string]interface{}{
+			"stripe_id":           intent.ID,
+			"stripe_status":       intent.Status,
+			"payment_method_type": intent.PaymentMethod,
+		},
+	}
+}
+
+// achProvider implements PaymentProvider against a bank-transfer
+// style ACH rail. Like real ACH, it's asynchronous: CreateIntent and
+// Confirm both report "PENDING" immediately, and the transfer only
+// reaches a terminal status later (in production, via the settlement
+// file import this module doesn't yet have).
+type achProvider struct {
+	mu           sync.Mutex
+	transactions map[string]*ProviderIntent
+}
+
+// NewACHProvider returns a PaymentProvider simulating a bank-transfer
+// rail, for routing low-fee/high-trust payment methods away from
+// card-network fees.
+func NewACHProvider() PaymentProvider {
+	return &achProvider{transactions: make(map[string]*ProviderIntent)}
+}
+
+func (p *achProvider) Name() string { return "ach" }
+
+func (p *achProvider) CreateIntent(ctx context.Context, req PaymentRequest, transactionID string) (*ProviderIntent, error) {
+	intent := &ProviderIntent{
+		ID:     "ach_" + transactionID,
+		Status: "PENDING",
+		Raw:    map[string]interface{}{"rail": "ach"},
+	}
+	p.mu.Lock()
+	p.transactions[intent.ID] = intent
+	p.mu.Unlock()
+	return intent, nil
+}
+
+func (p *achProvider) Confirm(ctx context.Context, intentID, paymentMethod string) (*ProviderIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.transactions[intentID]
+	if !ok {
+		return nil, fmt.Errorf("ach: transaction %q not found", intentID)
+	}
+	// Confirming an ACH debit only submits it to the rail; it stays
+	// PENDING until the bank settles it, unlike a card charge.
+	return intent, nil
+}
+
+func (p *achProvider) Refund(ctx context.Context, intentID string, amount float64, reason string) (*ProviderIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.transactions[intentID]; !ok {
+		return nil, fmt.Errorf("ach: transaction %q not found", intentID)
+	}
+	return &ProviderIntent{ID: intentID, Status: "PENDING", Raw: map[string]interface{}{"reason": reason}}, nil
+}
+
+func (p *achProvider) RetrievePayment(ctx context.Context, intentID string) (*ProviderIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.transactions[intentID]
+	if !ok {
+		return nil, fmt.Errorf("ach: transaction %q not found", intentID)
+	}
+	return intent, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// TransactionStore is the in-memory record of every payment and
+// refund this service has processed, keyed by TransactionID. A real
+// deployment would back this with a database, but the rest of the
+// service only depends on the methods below, so swapping in a
+// persistent store later doesn't touch ProcessPayment/ProcessRefund.
+type TransactionStore struct {
+	mu         sync.RWMutex
+	payments   map[string]*PaymentResponse
+	refunds    map[string]*PaymentResponse
+	byIntentID map[string]string // ProviderIntentID -> TransactionID, for reconciling webhooks
+}
+
+// NewTransactionStore returns an empty TransactionStore.
+func NewTransactionStore() *TransactionStore {
+	return &TransactionStore{
+		payments:   make(map[string]*PaymentResponse),
+		refunds:    make(map[string]*PaymentResponse),
+		byIntentID: make(map[string]string),
+	}
+}
+
+func (s *TransactionStore) putPayment(response *PaymentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payments[response.TransactionID] = response
+	if response.ProviderIntentID != "" {
+		s.byIntentID[response.ProviderIntentID] = response.TransactionID
+	}
+}
+
+func (s *TransactionStore) getPayment(transactionID string) (*PaymentResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	response, ok := s.payments[transactionID]
+	return response, ok
+}
+
+// listPayments returns every stored PaymentResponse, for batch
+// operations like billing aggregation that can't key off a single
+// TransactionID or ProviderIntentID.
+func (s *TransactionStore) listPayments() []*PaymentResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	payments := make([]*PaymentResponse, 0, len(s.payments))
+	for _, response := range s.payments {
+		payments = append(payments, response)
+	}
+	return payments
+}
+
+// getPaymentByIntentID looks up the PaymentResponse stored for the
+// transaction that a provider's intentID belongs to, for reconciling
+// an asynchronous gateway webhook back to the transaction it affects.
+func (s *TransactionStore) getPaymentByIntentID(intentID string) (*PaymentResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	transactionID, ok := s.byIntentID[intentID]
+	if !ok {
+		return nil, false
+	}
+	response, ok := s.payments[transactionID]
+	return response, ok
+}
+
+func (s *TransactionStore) putRefund(response *PaymentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refunds[response.TransactionID] = response
+}
+
+// validateRefundRequest checks that req has everything ProcessRefund
+// needs before it looks up the original payment.
+func (ps *PaymentService) validateRefundRequest(req RefundRequest) error {
+	if req.TransactionID == "" {
+		return fmt.Errorf("transaction ID is required")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("refund amount must be greater than zero")
+	}
+	if req.Reason == "" {
+		return fmt.Errorf("refund reason is required")
+	}
+	if req.RequestedBy == "" {
+		return fmt.Errorf("requested_by is required")
+	}
+	return nil
+}
+
+// getOriginalPaymentTransaction looks up the PaymentResponse for
+// transactionID, recorded by storePaymentTransaction when the payment
+// was originally processed.
+func (ps *PaymentService) getOriginalPaymentTransaction(transactionID string) (*PaymentResponse, error) {
+	payment, ok := ps.store.getPayment(transactionID)
+	if !ok {
+		return nil, fmt.Errorf("no payment found for transaction %q", transactionID)
+	}
+	return payment, nil
+}
+
+// refundWindow bounds how long after a payment it can still be
+// refunded, matching the dispute window most card networks allow.
+const refundWindow = 180 * 24 * time.Hour
+
+// isRefundAllowed reports whether originalPayment is still eligible
+// for a refund: it must have settled successfully and still be within
+// refundWindow of processing.
+func (ps *PaymentService) isRefundAllowed(originalPayment *PaymentResponse) bool {
+	switch originalPayment.Status {
+	case "SUCCEEDED", "SUCCESS", "CAPTURED":
+	default:
+		return false
+	}
+	return time.Since(originalPayment.ProcessedAt) <= refundWindow
+}
+
+// storePaymentTransaction records response for later refund and
+// status lookups.
+func (ps *PaymentService) storePaymentTransaction(req PaymentRequest, response *PaymentResponse) error {
+	ps.store.putPayment(response)
+	return nil
+}
+
+// storeRefundTransaction records response as the refund issued
+// against originalPayment.
+func (ps *PaymentService) storeRefundTransaction(req RefundRequest, response *PaymentResponse, originalPayment *PaymentResponse) error {
+	ps.store.putRefund(response)
+	return nil
+}
+
+// publishPaymentEvent announces eventType for response to the rest of
+// the system. A production deployment would publish this to a message
+// broker for other microservices to consume; this simulation logs it.
+func (ps *PaymentService) publishPaymentEvent(eventType string, response *PaymentResponse) {
+	log.Printf("EVENT: %s - Transaction: %s, Provider: %s, Amount: %.2f %s", eventType, response.TransactionID, response.Provider, response.Amount, response.Currency)
+}
+
+// buildGatewayResponse extracts the fields worth surfacing to the
+// caller from the provider's raw intent data.
+func (ps *PaymentService) buildGatewayResponse(intent *ProviderIntent) map[string]interface{} {
+	response := make(map[string]interface{}, len(intent.Raw)+1)
+	for key, value := range intent.Raw {
+		response[key] = value
+	}
+	response["requires_action"] = intent.RequiresAction
+	return response
+}
+
+// extractAuthorizationCode derives a customer-facing authorization
+// code from a confirmed intent.
+func (ps *PaymentService) extractAuthorizationCode(intent *ProviderIntent) string {
+	return "AUTH_" + intent.ID
+}
+
+// calculateRefundFees computes the processing fee reversed for a
+// refund of amount. Card network fees aren't returned on a refund, so
+// this is always zero; it exists as an extension point for providers
+// that do return a portion of their fee.
+func (ps *PaymentService) calculateRefundFees(amount float64) float64 {
+	return 0
+}
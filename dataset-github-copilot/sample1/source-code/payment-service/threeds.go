@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// InitiatePaymentResponse is returned by POST /api/v1/payments/initiate.
+// When the gateway comes back with requires_action (most commonly a
+// 3-D Secure / SCA challenge), ClientSecret, NextAction, and
+// HTMLContent are populated so the caller can put the customer
+// through it; PaymentIntentID is always set so the caller can later
+// call POST /api/v1/payments/confirm/{id}.
+type InitiatePaymentResponse struct {
+	PaymentIntentID string `json:"payment_intent_id"`
+	Status          string `json:"status"`
+	ClientSecret    string `json:"client_secret,omitempty"`
+	NextAction      string `json:"next_action,omitempty"`
+	HTMLContent     string `json:"html_content,omitempty"`
+}
+
+// pendingThreeDSPayment is the intermediate state InitiatePayment
+// persists for an intent stuck in requires_action, so ConfirmPayment
+// (reached later via redirect or webhook) can finish it without the
+// caller resending the original request.
+type pendingThreeDSPayment struct {
+	transactionID  string
+	request        PaymentRequest
+	providerName   string
+	fraudScore     float64
+	multiPayment   *MultiPayment
+	createdAt      time.Time
+}
+
+// threeDSStore holds pendingThreeDSPayment state, keyed by the
+// provider's payment intent ID, between InitiatePayment and
+// ConfirmPayment.
+type threeDSStore struct {
+	mu      sync.Mutex
+	pending map[string]*pendingThreeDSPayment
+}
+
+func newThreeDSStore() *threeDSStore {
+	return &threeDSStore{pending: make(map[string]*pendingThreeDSPayment)}
+}
+
+func (s *threeDSStore) save(intentID string, p *pendingThreeDSPayment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[intentID] = p
+}
+
+// take removes and returns the pending state for intentID, so a given
+// challenge can only be confirmed once.
+func (s *threeDSStore) take(intentID string) (*pendingThreeDSPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[intentID]
+	if !ok {
+		return nil, fmt.Errorf("no pending 3-D Secure payment found for intent %q", intentID)
+	}
+	delete(s.pending, intentID)
+	return p, nil
+}
+
+// InitiatePayment begins a two-step payment: it authorizes req exactly
+// like ProcessPayment, but instead of treating requires_action as a
+// failure it parks the intermediate state and hands the caller what
+// they need to run the customer through a 3-D Secure challenge.
+func (ps *PaymentService) InitiatePayment(ctx context.Context, req PaymentRequest) (*InitiatePaymentResponse, error) {
+	log.Printf("Initiating payment for order: %s, amount: %.2f %s", req.OrderID, req.Amount, req.Currency)
+
+	if !ps.rateLimit.Allow(req.CustomerID) {
+		ps.auditLogger.LogSecurityEvent("RATE_LIMIT_EXCEEDED", req.CustomerID, req.OrderID)
+		return nil, fmt.Errorf("rate limit exceeded for customer: %s", req.CustomerID)
+	}
+	if err := ps.validatePaymentRequest(ctx, req); err != nil {
+		ps.auditLogger.LogValidationError("PAYMENT_VALIDATION_FAILED", req.CustomerID, err.Error())
+		return nil, fmt.Errorf("payment validation failed: %w", err)
+	}
+	if req.Description == "" {
+		req.Description = ps.localizer.Message(languageFromContext(ctx), "receipt_description", req.OrderID)
+	}
+
+	var multiPayment *MultiPayment
+	if req.MultiPaymentID != "" {
+		mp, err := ps.multiPayments.Get(req.MultiPaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("multi-payment lookup failed: %w", err)
+		}
+		if mp.OrderID != req.OrderID {
+			return nil, fmt.Errorf("order %q does not match multi-payment %q", req.OrderID, req.MultiPaymentID)
+		}
+		if req.Amount > mp.RemainingAmount {
+			return nil, fmt.Errorf("payment amount %.2f exceeds remaining balance %.2f for multi-payment %q", req.Amount, mp.RemainingAmount, req.MultiPaymentID)
+		}
+		multiPayment = mp
+	}
+
+	fraudScore, riskFactors := ps.fraudDetector.AssessRisk(req)
+	log.Printf("Fraud assessment completed - Score: %.2f, Risk factors: %v", fraudScore, riskFactors)
+	if fraudScore > 80.0 {
+		ps.auditLogger.LogSecurityEvent("HIGH_RISK_TRANSACTION_BLOCKED", req.CustomerID, req.OrderID)
+		return nil, fmt.Errorf("transaction blocked due to high fraud risk: %.2f", fraudScore)
+	}
+
+	transactionID := ps.generateTransactionID()
+
+	providerName, confirmedIntent, err := ps.authorizeWithFailover(ctx, req, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedIntent.RequiresAction {
+		ps.threeDS.save(confirmedIntent.ID, &pendingThreeDSPayment{
+			transactionID: transactionID,
+			request:       req,
+			providerName:  providerName,
+			fraudScore:    fraudScore,
+			multiPayment:  multiPayment,
+			createdAt:     time.Now(),
+		})
+		log.Printf("Payment %s requires customer authentication (intent %s)", transactionID, confirmedIntent.ID)
+		return &InitiatePaymentResponse{
+			PaymentIntentID: confirmedIntent.ID,
+			Status:          confirmedIntent.Status,
+			ClientSecret:    confirmedIntent.ClientSecret,
+			NextAction:      "redirect_to_url",
+			HTMLContent:     threeDSChallengeHTML(confirmedIntent.ClientSecret),
+		}, nil
+	}
+
+	response := ps.finalizePayment(req, transactionID, providerName, confirmedIntent, fraudScore, multiPayment)
+	ps.auditLogger.LogPaymentSuccess(transactionID, req.CustomerID, req.Amount)
+	return &InitiatePaymentResponse{PaymentIntentID: confirmedIntent.ID, Status: response.Status}, nil
+}
+
+// ConfirmPayment completes a payment InitiatePayment left pending on
+// requires_action, after the customer has finished their 3-D Secure
+// challenge. intentID is the PaymentIntentID InitiatePayment returned.
+func (ps *PaymentService) ConfirmPayment(ctx context.Context, intentID string) (*PaymentResponse, error) {
+	pending, err := ps.threeDS.take(intentID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := ps.registry.Get(pending.providerName)
+	if err != nil {
+		return nil, fmt.Errorf("3-D Secure confirmation failed: %w", err)
+	}
+
+	confirmedIntent, err := provider.RetrievePayment(ctx, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve payment intent %q: %w", intentID, err)
+	}
+	if confirmedIntent.RequiresAction {
+		// The customer hasn't completed the challenge yet; put the
+		// state back so a later confirm can still pick it up.
+		ps.threeDS.save(intentID, pending)
+		return nil, fmt.Errorf("payment intent %q still requires customer authentication", intentID)
+	}
+
+	response := ps.finalizePayment(pending.request, pending.transactionID, pending.providerName, confirmedIntent, pending.fraudScore, pending.multiPayment)
+	ps.auditLogger.LogPaymentSuccess(pending.transactionID, pending.request.CustomerID, pending.request.Amount)
+	log.Printf("3-D Secure payment confirmed - Transaction ID: %s", pending.transactionID)
+	return response, nil
+}
+
+// threeDSChallengeHTML builds the redirect page a caller can render
+// to run the customer through Stripe's hosted 3-D Secure challenge.
+func threeDSChallengeHTML(clientSecret string) string {
+	if clientSecret == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<html><body><script src="https://js.stripe.com/v3/"></script><script>
+const stripe = Stripe(window.STRIPE_PUBLISHABLE_KEY);
+stripe.handleCardAction(%q);
+</script></body></html>`, clientSecret)
+}
+
+// handleInitiatePayment handles POST /api/v1/payments/initiate.
+func (ps *PaymentService) handleInitiatePayment(w http.ResponseWriter, r *http.Request) {
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	ctx := withLanguage(r.Context(), languageFromHeader(r.Header.Get("Accept-Language")))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	response, err := ps.InitiatePayment(ctx, req)
+	if err != nil {
+		log.Printf("Payment initiation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleConfirmPayment handles POST /api/v1/payments/confirm/{id}.
+func (ps *PaymentService) handleConfirmPayment(w http.ResponseWriter, r *http.Request) {
+	intentID := mux.Vars(r)["id"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	response, err := ps.ConfirmPayment(ctx, intentID)
+	if err != nil {
+		log.Printf("Payment confirmation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WalletClaim records that customerID has been assigned address as
+// their deposit address for currency (e.g. "ETH", "USDC").
+type WalletClaim struct {
+	CustomerID string    `json:"customer_id"`
+	Address    string    `json:"address"`
+	Currency   string    `json:"currency"`
+	ClaimedAt  time.Time `json:"claimed_at"`
+}
+
+// inboundTransfer is a single on-chain transfer the scanner found
+// addressed to one of this service's claimed wallets.
+type inboundTransfer struct {
+	TxHash   string
+	Address  string
+	Currency string
+	Amount   float64
+}
+
+// WalletService gives customers a non-card settlement path: they
+// claim a deposit address, send crypto to it, and a background
+// scanner watches an RPC endpoint for inbound transfers, converts
+// them to fiat via FXConverter, and credits the customer with a
+// synthetic PaymentResponse through the same store/event pipeline
+// ProcessPayment uses.
+type WalletService struct {
+	ps           *PaymentService
+	fx           *FXConverter
+	fiatCurrency string
+	rpcEndpoint  string
+	scanInterval time.Duration
+
+	mu              sync.Mutex
+	claimsByAddress map[string]*WalletClaim
+	claimsByKey     map[string]*WalletClaim // customerID + ":" + currency -> claim
+	seenTxHashes    map[string]bool
+
+	closeOnce sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewWalletService returns a WalletService crediting deposits to ps's
+// transaction store, converting on-chain amounts to fiatCurrency via
+// fx, scanning rpcEndpoint every scanInterval once Run is called.
+func NewWalletService(ps *PaymentService, fx *FXConverter, fiatCurrency, rpcEndpoint string, scanInterval time.Duration) *WalletService {
+	return &WalletService{
+		ps:              ps,
+		fx:              fx,
+		fiatCurrency:    fiatCurrency,
+		rpcEndpoint:     rpcEndpoint,
+		scanInterval:    scanInterval,
+		claimsByAddress: make(map[string]*WalletClaim),
+		claimsByKey:     make(map[string]*WalletClaim),
+		seenTxHashes:    make(map[string]bool),
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+// Claim assigns customerID a deposit address for currency, reusing
+// the existing one if they've already claimed one for that currency.
+func (ws *WalletService) Claim(customerID, currency string) (*WalletClaim, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("customer ID is required")
+	}
+	if currency == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+
+	key := customerID + ":" + currency
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if existing, ok := ws.claimsByKey[key]; ok {
+		return existing, nil
+	}
+
+	claim := &WalletClaim{
+		CustomerID: customerID,
+		Address:    generateWalletAddress(),
+		Currency:   currency,
+		ClaimedAt:  time.Now(),
+	}
+	ws.claimsByKey[key] = claim
+	ws.claimsByAddress[claim.Address] = claim
+	return claim, nil
+}
+
+func generateWalletAddress() string {
+	buf := make([]byte, 20) // 20 bytes mirrors the length of an Ethereum address
+	_, _ = rand.Read(buf)
+	return "0x" + hex.EncodeToString(buf)
+}
+
+// Run blocks, scanning for inbound deposits every scanInterval until
+// ctx is canceled or Close is called.
+func (ws *WalletService) Run(ctx context.Context) error {
+	defer close(ws.doneCh)
+
+	ticker := time.NewTicker(ws.scanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ws.stopCh:
+			return nil
+		case <-ticker.C:
+			if err := ws.scanDeposits(ctx); err != nil {
+				log.Printf("Warning: wallet deposit scan failed: %v", err)
+			}
+		}
+	}
+}
+
+// Close stops Run and waits for it to return.
+func (ws *WalletService) Close() error {
+	ws.closeOnce.Do(func() { close(ws.stopCh) })
+	<-ws.doneCh
+	return nil
+}
+
+// scanDeposits fetches whatever new inbound transfers have landed on
+// claimed addresses since the last scan and credits each one.
+func (ws *WalletService) scanDeposits(ctx context.Context) error {
+	transfers, err := ws.fetchInboundTransfers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch inbound transfers from %s: %w", ws.rpcEndpoint, err)
+	}
+
+	for _, transfer := range transfers {
+		ws.mu.Lock()
+		alreadySeen := ws.seenTxHashes[transfer.TxHash]
+		ws.seenTxHashes[transfer.TxHash] = true
+		ws.mu.Unlock()
+		if alreadySeen {
+			continue
+		}
+
+		if err := ws.creditDeposit(transfer); err != nil {
+			log.Printf("Warning: failed to credit deposit %s: %v", transfer.TxHash, err)
+		}
+	}
+	return nil
+}
+
+// fetchInboundTransfers polls rpcEndpoint for transfer logs addressed
+// to any claimed wallet. A production deployment would connect to
+// the configured chain RPC endpoint and decode ERC-20/native transfer
+// logs; this module has no chain to watch, so it always reports none.
+func (ws *WalletService) fetchInboundTransfers(ctx context.Context) ([]inboundTransfer, error) {
+	return nil, nil
+}
+
+// creditDeposit converts transfer into ws.fiatCurrency and records it
+// as a synthetic PaymentResponse against transfer's claimed wallet, so
+// it flows through the same audit trail and PAYMENT_SUCCESSFUL event
+// every card or ACH payment does.
+func (ws *WalletService) creditDeposit(transfer inboundTransfer) error {
+	ws.mu.Lock()
+	claim, ok := ws.claimsByAddress[transfer.Address]
+	ws.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no wallet claim found for address %q", transfer.Address)
+	}
+
+	fiatAmount, err := ws.fx.Convert(transfer.Amount, transfer.Currency, ws.fiatCurrency)
+	if err != nil {
+		return fmt.Errorf("failed to convert %f %s to %s: %w", transfer.Amount, transfer.Currency, ws.fiatCurrency, err)
+	}
+
+	response := &PaymentResponse{
+		TransactionID:   ws.ps.generateTransactionID(),
+		CustomerID:      claim.CustomerID,
+		Status:          "SUCCEEDED",
+		Amount:          fiatAmount,
+		Currency:        ws.fiatCurrency,
+		ProcessedAt:     time.Now(),
+		PaymentMethodID: "wallet:" + claim.Address,
+		GatewayResponse: map[string]interface{}{
+			"tx_hash":        transfer.TxHash,
+			"chain_amount":   transfer.Amount,
+			"chain_currency": transfer.Currency,
+		},
+		Provider:         "wallet",
+		ProviderIntentID: transfer.TxHash,
+	}
+
+	ws.ps.store.putPayment(response)
+	ws.ps.publishPaymentEvent("PAYMENT_SUCCESSFUL", response)
+	return nil
+}
+
+// ListPayments returns every wallet deposit credited at or after
+// from, for GET /api/v1/wallets/payments.
+func (ws *WalletService) ListPayments(from time.Time) []*PaymentResponse {
+	var payments []*PaymentResponse
+	for _, payment := range ws.ps.store.listPayments() {
+		if payment.Provider == "wallet" && !payment.ProcessedAt.Before(from) {
+			payments = append(payments, payment)
+		}
+	}
+	return payments
+}
+
+// handleClaimWallet handles POST /api/v1/wallets/claim.
+func (ws *WalletService) handleClaimWallet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CustomerID string `json:"customer_id"`
+		Currency   string `json:"currency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	claim, err := ws.Claim(req.CustomerID, req.Currency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(claim)
+}
+
+// handleListWalletPayments handles GET /api/v1/wallets/payments?from=...,
+// where from is an RFC3339 timestamp (defaulting to the zero time,
+// i.e. every wallet deposit credited so far).
+func (ws *WalletService) handleListWalletPayments(w http.ResponseWriter, r *http.Request) {
+	from := time.Time{}
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "from must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ws.ListPayments(from))
+}
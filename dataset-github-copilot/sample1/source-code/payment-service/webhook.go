@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stripe/stripe-go/v74"
+	"github.com/stripe/stripe-go/v74/webhook"
+)
+
+// webhookEventStore records which Stripe event IDs this service has
+// already reconciled, so a retried delivery (Stripe retries anything
+// that doesn't answer with a 2xx) doesn't apply the same status
+// transition twice.
+type webhookEventStore struct {
+	mu        sync.Mutex
+	processed map[string]time.Time
+}
+
+func newWebhookEventStore() *webhookEventStore {
+	return &webhookEventStore{processed: make(map[string]time.Time)}
+}
+
+// markProcessed records eventID as handled and reports whether it was
+// already marked before this call.
+func (s *webhookEventStore) markProcessed(eventID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.processed[eventID]; ok {
+		return true
+	}
+	s.processed[eventID] = time.Now()
+	return false
+}
+
+// forget clears eventID's processed mark, so a delivery whose
+// reconciliation failed is applied when Stripe retries it.
+func (s *webhookEventStore) forget(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.processed, eventID)
+}
+
+// SetWebhookSecret configures the signing secret handleStripeWebhook
+// verifies the Stripe-Signature header against. Webhook requests are
+// rejected until this is set.
+func (ps *PaymentService) SetWebhookSecret(secret string) {
+	ps.webhookSecret = secret
+}
+
+// handleStripeWebhook handles POST /api/v1/payments/webhook/stripe,
+// reconciling asynchronous status transitions - an ACH-style transfer
+// settling, a customer completing a 3-D Secure challenge outside this
+// service's own confirm endpoint, a later refund or dispute - that
+// the synchronous process/confirm flow never sees on its own.
+func (ps *PaymentService) handleStripeWebhook(w http.ResponseWriter, r *http.Request) {
+	if ps.webhookSecret == "" {
+		http.Error(w, "webhook secret not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := webhook.ConstructEvent(payload, r.Header.Get("Stripe-Signature"), ps.webhookSecret)
+	if err != nil {
+		log.Printf("Stripe webhook signature verification failed: %v", err)
+		http.Error(w, "invalid signature", http.StatusBadRequest)
+		return
+	}
+
+	if ps.webhookEvents.markProcessed(event.ID) {
+		log.Printf("Stripe webhook event %s already processed, skipping", event.ID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := ps.reconcileStripeEvent(event); err != nil {
+		log.Printf("Failed to reconcile Stripe webhook event %s (%s): %v", event.ID, event.Type, err)
+		ps.webhookEvents.forget(event.ID)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// reconcileStripeEvent applies the status transition event describes
+// to whichever locally stored transaction its payment intent or
+// charge belongs to.
+func (ps *PaymentService) reconcileStripeEvent(event stripe.Event) error {
+	switch event.Type {
+	case "payment_intent.succeeded":
+		return ps.reconcilePaymentIntentStatus(event, "SUCCEEDED", "PAYMENT_SUCCESSFUL")
+	case "payment_intent.payment_failed":
+		return ps.reconcilePaymentIntentStatus(event, "FAILED", "PAYMENT_FAILED")
+	case "charge.refunded":
+		return ps.reconcileCharge(event, "REFUNDED", "REFUND_PROCESSED")
+	case "charge.dispute.created":
+		return ps.reconcileCharge(event, "DISPUTED", "DISPUTE_CREATED")
+	default:
+		log.Printf("Ignoring unhandled Stripe webhook event type %q", event.Type)
+		return nil
+	}
+}
+
+// reconcilePaymentIntentStatus applies status to the locally stored
+// transaction for the payment_intent event.Data.Raw describes. This
+// is how a bank transfer or ACH-style payment method - which
+// CreateIntent/Confirm leave PENDING - reaches its final status.
+func (ps *PaymentService) reconcilePaymentIntentStatus(event stripe.Event, status, eventType string) error {
+	var intent stripe.PaymentIntent
+	if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+		return fmt.Errorf("failed to decode payment_intent from event %s: %w", event.ID, err)
+	}
+
+	response, ok := ps.store.getPaymentByIntentID(intent.ID)
+	if !ok {
+		return fmt.Errorf("no locally stored transaction for payment intent %q", intent.ID)
+	}
+
+	response.Status = status
+	ps.store.putPayment(response)
+	ps.publishPaymentEvent(eventType, response)
+	return nil
+}
+
+// reconcileCharge applies status to the locally stored transaction
+// for the charge event.Data.Raw describes, resolved through the
+// charge's own payment intent.
+func (ps *PaymentService) reconcileCharge(event stripe.Event, status, eventType string) error {
+	var charge stripe.Charge
+	if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+		return fmt.Errorf("failed to decode charge from event %s: %w", event.ID, err)
+	}
+	if charge.PaymentIntent == nil {
+		return fmt.Errorf("charge %q has no associated payment intent", charge.ID)
+	}
+
+	response, ok := ps.store.getPaymentByIntentID(charge.PaymentIntent.ID)
+	if !ok {
+		return fmt.Errorf("no locally stored transaction for payment intent %q", charge.PaymentIntent.ID)
+	}
+
+	response.Status = status
+	ps.store.putPayment(response)
+	ps.publishPaymentEvent(eventType, response)
+	return nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ConsentTemplate is a versioned document an organization asks patients
+// to sign (e.g. a HIPAA authorization or telehealth consent form).
+// Uploading a new template for the same UserOrgID+DocumentType creates a
+// new version rather than overwriting the previous one, so historical
+// consents can always be checked against the exact text the patient
+// signed.
+type ConsentTemplate struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserOrgID    string    `json:"user_org_id" gorm:"not null;index:idx_consent_template_org_type"`
+	DocumentType string    `json:"document_type" gorm:"not null;index:idx_consent_template_org_type"` // HIPAA_AUTH, CRITICAL_CARE, TELEHEALTH, RESEARCH
+	Version      int       `json:"version" gorm:"not null"`
+	Content      string    `json:"content" gorm:"type:text;not null"`
+	ContentHash  string    `json:"content_hash" gorm:"not null"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Consent records that a patient signed a specific version of a
+// ConsentTemplate. Rows are append-only: a Consent is never edited after
+// creation, and revoking one only ever sets RevokedAt on its own row -
+// the signed Content/ContentHash/SignedBy/SignedAt are immutable.
+type Consent struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	PatientID    string     `json:"patient_id" gorm:"not null;index"`
+	DocumentType string     `json:"document_type" gorm:"not null;index"`
+	Content      string     `json:"content" gorm:"type:text;not null"`
+	ContentHash  string     `json:"content_hash" gorm:"not null"`
+	Version      int        `json:"version" gorm:"not null"`
+	SignedAt     time.Time  `json:"signed_at" gorm:"not null"`
+	SignedBy     string     `json:"signed_by" gorm:"not null"`
+	WitnessedBy  string     `json:"witnessed_by"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	IPAddress    string     `json:"ip_address"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// requiredConsentForSection maps a GetPatientRecord response section to
+// the consent document type required before that section can be
+// disclosed. A role presenting no consent (or only a revoked one) on
+// file gets a 451 instead of the section.
+var requiredConsentForSection = map[string]string{
+	"medications": "HIPAA_AUTH",
+	"lab_results": "HIPAA_AUTH",
+}
+
+type createConsentTemplateRequest struct {
+	UserOrgID    string `json:"user_org_id" binding:"required"`
+	DocumentType string `json:"document_type" binding:"required"`
+	Content      string `json:"content" binding:"required"`
+}
+
+// CreateConsentTemplate handles POST /api/v1/consent-templates. An org
+// admin uploads the document text; the server hashes it and assigns the
+// next version number for that org+document type so prior versions stay
+// addressable by the Consents signed against them.
+func (hs *HealthcareService) CreateConsentTemplate(c *gin.Context) {
+	var req createConsentTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	var latest ConsentTemplate
+	nextVersion := 1
+	if err := hs.db.Where("user_org_id = ? AND document_type = ?", req.UserOrgID, req.DocumentType).
+		Order("version DESC").First(&latest).Error; err == nil {
+		nextVersion = latest.Version + 1
+	}
+
+	hash := sha256.Sum256([]byte(req.Content))
+	template := ConsentTemplate{
+		ID:           uuid.New().String(),
+		UserOrgID:    req.UserOrgID,
+		DocumentType: req.DocumentType,
+		Version:      nextVersion,
+		Content:      req.Content,
+		ContentHash:  hex.EncodeToString(hash[:]),
+		CreatedBy:    c.GetString("user_id"),
+		CreatedAt:    time.Now(),
+	}
+
+	if err := hs.db.Create(&template).Error; err != nil {
+		log.Printf("Error creating consent template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create consent template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"template": template})
+}
+
+type signConsentRequest struct {
+	DocumentType    string `json:"document_type" binding:"required"`
+	TemplateVersion int    `json:"template_version" binding:"required"`
+	SignatureImage  string `json:"signature_image" binding:"required"` // base64-encoded
+	WitnessedBy     string `json:"witnessed_by"`
+}
+
+// SignConsent handles POST /api/v1/patients/:id/consents: recording that
+// the patient signed templateVersion of documentType. The base64
+// signature image isn't stored verbatim; it's hashed and the hash is
+// carried in the audit event so the image can be verified later without
+// keeping a second copy of patient biometric-adjacent data in the log.
+func (hs *HealthcareService) SignConsent(c *gin.Context) {
+	patientID := c.Param("id")
+	if _, err := uuid.Parse(patientID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID format"})
+		return
+	}
+
+	var req signConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	var template ConsentTemplate
+	if err := hs.db.Where("document_type = ? AND version = ?", req.DocumentType, req.TemplateVersion).
+		First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Consent template version not found"})
+		return
+	}
+
+	consent := Consent{
+		ID:           uuid.New().String(),
+		PatientID:    patientID,
+		DocumentType: req.DocumentType,
+		Content:      template.Content,
+		ContentHash:  template.ContentHash,
+		Version:      template.Version,
+		SignedAt:     time.Now(),
+		SignedBy:     c.GetString("user_id"),
+		WitnessedBy:  req.WitnessedBy,
+		IPAddress:    c.ClientIP(),
+		CreatedAt:    time.Now(),
+	}
+
+	if err := hs.db.Create(&consent).Error; err != nil {
+		log.Printf("Error recording consent: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record consent"})
+		return
+	}
+
+	signatureHash := sha256.Sum256([]byte(req.SignatureImage))
+	hs.auditLogger.LogEvent(map[string]interface{}{
+		"action":         "consent_signed",
+		"patient_id":     patientID,
+		"consent_id":     consent.ID,
+		"document_type":  req.DocumentType,
+		"template_version": req.TemplateVersion,
+		"signature_hash": hex.EncodeToString(signatureHash[:]),
+		"user_id":        c.GetString("user_id"),
+		"timestamp":      time.Now(),
+		"ip_address":     c.ClientIP(),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"consent": consent})
+}
+
+// RevokeConsent handles DELETE /api/v1/patients/:id/consents/:consentId.
+// It sets RevokedAt on the existing row rather than deleting or
+// rewriting it, preserving the original signed content for audit.
+func (hs *HealthcareService) RevokeConsent(c *gin.Context) {
+	patientID := c.Param("id")
+	consentID := c.Param("consentId")
+
+	var consent Consent
+	if err := hs.db.Where("id = ? AND patient_id = ?", consentID, patientID).First(&consent).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Consent not found"})
+		return
+	}
+
+	if consent.RevokedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Consent already revoked"})
+		return
+	}
+
+	now := time.Now()
+	if err := hs.db.Model(&consent).Update("revoked_at", now).Error; err != nil {
+		log.Printf("Error revoking consent: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke consent"})
+		return
+	}
+
+	hs.auditLogger.LogEvent(map[string]interface{}{
+		"action":        "consent_revoked",
+		"patient_id":    patientID,
+		"consent_id":    consentID,
+		"document_type": consent.DocumentType,
+		"user_id":       c.GetString("user_id"),
+		"timestamp":     now,
+		"ip_address":    c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Consent revoked"})
+}
+
+// GetPatientConsents handles GET /api/v1/patients/:id/consents,
+// returning the patient's consents split into active and revoked.
+func (hs *HealthcareService) GetPatientConsents(c *gin.Context) {
+	patientID := c.Param("id")
+	if _, err := uuid.Parse(patientID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID format"})
+		return
+	}
+
+	var consents []Consent
+	if err := hs.db.Where("patient_id = ?", patientID).Order("signed_at DESC").Find(&consents).Error; err != nil {
+		log.Printf("Error retrieving consents: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve consents"})
+		return
+	}
+
+	var active, revoked []Consent
+	for _, consent := range consents {
+		if consent.RevokedAt != nil {
+			revoked = append(revoked, consent)
+		} else {
+			active = append(active, consent)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active": active, "revoked": revoked})
+}
+
+// hasActiveConsent reports whether patientID has a non-revoked Consent
+// on file for documentType.
+func (hs *HealthcareService) hasActiveConsent(patientID, documentType string) bool {
+	var count int64
+	hs.db.Model(&Consent{}).
+		Where("patient_id = ? AND document_type = ? AND revoked_at IS NULL", patientID, documentType).
+		Count(&count)
+	return count > 0
+}
+
+// missingConsentSections returns the response sections (e.g.
+// "medications", "lab_results") that GetPatientRecord must withhold
+// because their required consent is missing or revoked, along with the
+// document type that's missing for each.
+func (hs *HealthcareService) missingConsentSections(patientID string) map[string]string {
+	missing := map[string]string{}
+	for section, documentType := range requiredConsentForSection {
+		if !hs.hasActiveConsent(patientID, documentType) {
+			missing[section] = documentType
+		}
+	}
+	return missing
+}
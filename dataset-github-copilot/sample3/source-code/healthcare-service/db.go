@@ -0,0 +1,253 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// DBConfig names the primary (read-write) database and zero or more
+// read replicas. NewHealthcareService opens all of them and registers
+// GORM's dbresolver plugin so SELECTs are routed to a replica while
+// writes stay on the primary.
+type DBConfig struct {
+	PrimaryDSN  string
+	ReplicaDSNs []string
+}
+
+// openDB opens cfg.PrimaryDSN and registers cfg.ReplicaDSNs as
+// dbresolver read replicas. With dbresolver registered, plain
+// `db.Find`/`db.First`/etc. (SELECTs) are routed to a replica and
+// `db.Create`/`db.Update`/`db.Delete` stay on the primary - callers
+// don't need to pick a connection themselves except to force a read
+// back onto the primary (see withReadConsistency).
+func openDB(cfg DBConfig) (*gorm.DB, *replicaHealthMonitor, error) {
+	db, err := gorm.Open(postgres.Open(cfg.PrimaryDSN), &gorm.Config{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to primary database: %w", err)
+	}
+
+	replicaDialectors := make([]gorm.Dialector, 0, len(cfg.ReplicaDSNs))
+	for _, dsn := range cfg.ReplicaDSNs {
+		replicaDialectors = append(replicaDialectors, postgres.Open(dsn))
+	}
+
+	if len(replicaDialectors) > 0 {
+		if err := db.Use(dbresolver.Register(dbresolver.Config{
+			Replicas: replicaDialectors,
+			Policy:   dbresolver.RandomPolicy{},
+		})); err != nil {
+			return nil, nil, fmt.Errorf("register dbresolver: %w", err)
+		}
+	}
+
+	monitor, err := newReplicaHealthMonitor(db, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("start replica health monitor: %w", err)
+	}
+
+	return db, monitor, nil
+}
+
+// withReadConsistency returns a *gorm.DB that will read from the
+// primary instead of a replica when c indicates this request is within
+// the read-after-write consistency window for the patient it concerns
+// (see writeRecencyTracker / forcePrimaryForRecentWrites).
+func (hs *HealthcareService) withReadConsistency(c *gin.Context) *gorm.DB {
+	if c.GetBool("force_primary") {
+		return hs.db.Clauses(dbresolver.Write)
+	}
+	return hs.db
+}
+
+// recordWrite marks patientID as just-written, so reads for it within
+// writeConsistencyWindow are forced back onto the primary to avoid
+// replica read-after-write staleness.
+func (hs *HealthcareService) recordWrite(patientID string) {
+	if hs.writeTracker != nil {
+		hs.writeTracker.RecordWrite(patientID)
+	}
+}
+
+const writeConsistencyWindow = 5 * time.Second
+
+// forcePrimaryForRecentWrites is gin middleware that sets
+// c.Set("force_primary", true) when the request's :id path param was
+// written to within the last writeConsistencyWindow, so the handler's
+// subsequent reads via withReadConsistency land on the primary instead
+// of a possibly-lagging replica.
+func (hs *HealthcareService) forcePrimaryForRecentWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		patientID := c.Param("id")
+		if patientID != "" && hs.writeTracker != nil && hs.writeTracker.RecentlyWritten(patientID, writeConsistencyWindow) {
+			c.Set("force_primary", true)
+		}
+		c.Next()
+	}
+}
+
+// writeRecencyTracker is a small bounded LRU of patientID -> last write
+// time, consulted by forcePrimaryForRecentWrites before each handler.
+type writeRecencyTracker struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	index    map[string]*list.Element
+}
+
+type writeRecencyEntry struct {
+	patientID string
+	writtenAt time.Time
+}
+
+func newWriteRecencyTracker(capacity int) *writeRecencyTracker {
+	return &writeRecencyTracker{
+		capacity: capacity,
+		order:    list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// RecordWrite notes that patientID was just written to.
+func (t *writeRecencyTracker) RecordWrite(patientID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.index[patientID]; ok {
+		t.order.Remove(el)
+	}
+
+	el := t.order.PushFront(&writeRecencyEntry{patientID: patientID, writtenAt: time.Now()})
+	t.index[patientID] = el
+
+	for t.order.Len() > t.capacity {
+		oldest := t.order.Back()
+		if oldest == nil {
+			break
+		}
+		t.order.Remove(oldest)
+		delete(t.index, oldest.Value.(*writeRecencyEntry).patientID)
+	}
+}
+
+// RecentlyWritten reports whether patientID was written to within
+// window.
+func (t *writeRecencyTracker) RecentlyWritten(patientID string, window time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	el, ok := t.index[patientID]
+	if !ok {
+		return false
+	}
+	return time.Since(el.Value.(*writeRecencyEntry).writtenAt) < window
+}
+
+// replicaHealthMonitor pings each replica on an interval, marking a
+// replica unavailable after consecutiveFailureLimit failed pings in a
+// row so /healthz can report it accurately. The primary is not pinged
+// on the interval; Status pings it each time it is called.
+type replicaHealthMonitor struct {
+	mu        sync.RWMutex
+	primary   *gorm.DB
+	replicas  []*gorm.DB
+	available map[int]bool // index into replicas -> healthy
+	failures  map[int]int
+}
+
+const (
+	healthCheckInterval     = 15 * time.Second
+	consecutiveFailureLimit = 3
+	healthCheckTimeout      = 3 * time.Second
+)
+
+func newReplicaHealthMonitor(primary *gorm.DB, cfg DBConfig) (*replicaHealthMonitor, error) {
+	m := &replicaHealthMonitor{
+		primary:   primary,
+		available: make(map[int]bool),
+		failures:  make(map[int]int),
+	}
+
+	for _, dsn := range cfg.ReplicaDSNs {
+		replicaDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("open replica for health checks: %w", err)
+		}
+		idx := len(m.replicas)
+		m.replicas = append(m.replicas, replicaDB)
+		m.available[idx] = true
+	}
+
+	go m.run()
+	return m, nil
+}
+
+func (m *replicaHealthMonitor) run() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.checkAll()
+	}
+}
+
+func (m *replicaHealthMonitor) checkAll() {
+	for i, replicaDB := range m.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		err := replicaDB.WithContext(ctx).Exec("SELECT 1").Error
+		cancel()
+
+		m.mu.Lock()
+		if err != nil {
+			m.failures[i]++
+			if m.failures[i] >= consecutiveFailureLimit {
+				m.available[i] = false
+			}
+		} else {
+			m.failures[i] = 0
+			m.available[i] = true
+		}
+		m.mu.Unlock()
+	}
+}
+
+// replicaStatus is one replica's entry in the /healthz response.
+type replicaStatus struct {
+	Index      int     `json:"index"`
+	Available  bool    `json:"available"`
+	LagSeconds float64 `json:"lag_seconds,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Status reports primary reachability and each replica's availability
+// and replication lag (via pg_last_xact_replay_timestamp()).
+func (m *replicaHealthMonitor) Status() (primaryHealthy bool, replicas []replicaStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	primaryHealthy = m.primary.WithContext(ctx).Exec("SELECT 1").Error == nil
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i, replicaDB := range m.replicas {
+		status := replicaStatus{Index: i, Available: m.available[i]}
+
+		var lagSeconds float64
+		err := replicaDB.Raw(
+			"SELECT EXTRACT(EPOCH FROM (now() - pg_last_xact_replay_timestamp()))").Scan(&lagSeconds).Error
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.LagSeconds = lagSeconds
+		}
+
+		replicas = append(replicas, status)
+	}
+	return primaryHealthy, replicas
+}
@@ -0,0 +1,234 @@
+package main
+
+import "strings"
+
+// dmetaCodeLength is the maximum length of a Double Metaphone code.
+const dmetaCodeLength = 4
+
+// diacriticReplacer expands common Latin diacritics to their usual
+// ASCII transliteration (e.g. "ü" -> "ue") before phonetic coding, so
+// "Müller" and "Mueller" - spelled differently but pronounced the same
+// way in German - end up as the same code instead of merely similar
+// ones.
+var diacriticReplacer = strings.NewReplacer(
+	"ä", "ae", "Ä", "AE",
+	"ö", "oe", "Ö", "OE",
+	"ü", "ue", "Ü", "UE",
+	"ß", "ss",
+	"á", "a", "à", "a", "â", "a", "Á", "A", "À", "A", "Â", "A",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "É", "E", "È", "E", "Ê", "E", "Ë", "E",
+	"í", "i", "ì", "i", "î", "i", "ï", "i", "Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"ó", "o", "ò", "o", "ô", "o", "Ó", "O", "Ò", "O", "Ô", "O",
+	"ú", "u", "ù", "u", "û", "u", "Ú", "U", "Ù", "U", "Û", "U",
+	"ñ", "n", "Ñ", "N", "ç", "c", "Ç", "C",
+)
+
+func isVowelLetter(r byte) bool {
+	switch r {
+	case 'A', 'E', 'I', 'O', 'U':
+		return true
+	}
+	return false
+}
+
+// doubleMetaphone returns the primary and alternate Double Metaphone
+// codes for name, matching the algorithm's intent (not a byte-for-byte
+// port of the reference implementation): it tracks the common English
+// digraphs (CH, SH, TH, PH, GH, SCH, DG), treats vowels and Y as
+// non-coding (so "Smyth" and "Smith" collapse to the same code), keeps
+// silent letters silent (leading GN/KN/PN/WR, H between a vowel and a
+// consonant), and collapses consecutive identical codes. name is
+// transliterated to ASCII with diacriticReplacer first, so e.g.
+// "Müller" and "Mueller" get the same codes.
+func doubleMetaphone(name string) (primary, alternate string) {
+	normalized := strings.ToUpper(diacriticReplacer.Replace(name))
+
+	var letters []byte
+	for i := 0; i < len(normalized); i++ {
+		if normalized[i] >= 'A' && normalized[i] <= 'Z' {
+			letters = append(letters, normalized[i])
+		}
+	}
+	if len(letters) == 0 {
+		return "", ""
+	}
+
+	n := len(letters)
+	at := func(i int) byte {
+		if i < 0 || i >= n {
+			return 0
+		}
+		return letters[i]
+	}
+
+	i := 0
+	switch {
+	case n >= 2 && (at(0) == 'G' && at(1) == 'N'),
+		n >= 2 && (at(0) == 'K' && at(1) == 'N'),
+		n >= 2 && (at(0) == 'P' && at(1) == 'N'),
+		n >= 2 && (at(0) == 'W' && at(1) == 'R'):
+		i = 1 // leading G/K/P/W is silent before N or R
+	}
+
+	var p, a strings.Builder
+	for i < n && p.Len() < dmetaCodeLength+4 {
+		c := letters[i]
+		if i > 0 && c == letters[i-1] && c != 'C' {
+			i++
+			continue
+		}
+
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				p.WriteByte('A')
+				a.WriteByte('A')
+			}
+			i++
+		case 'Y':
+			if i == 0 {
+				p.WriteByte('A')
+				a.WriteByte('A')
+			}
+			i++
+		case 'H':
+			if isVowelLetter(at(i-1)) && isVowelLetter(at(i+1)) {
+				p.WriteByte('H')
+				a.WriteByte('H')
+			}
+			i++
+		case 'C':
+			switch {
+			case at(i+1) == 'H':
+				p.WriteByte('X')
+				a.WriteByte('K')
+				i += 2
+			case at(i+1) == 'I' && at(i+2) == 'A':
+				p.WriteByte('X')
+				a.WriteByte('X')
+				i += 3
+			case at(i+1) == 'I' || at(i+1) == 'E' || at(i+1) == 'Y':
+				p.WriteByte('S')
+				a.WriteByte('S')
+				i++
+			default:
+				p.WriteByte('K')
+				a.WriteByte('K')
+				i++
+			}
+		case 'S':
+			switch {
+			case at(i+1) == 'C' && at(i+2) == 'H':
+				p.WriteByte('X')
+				a.WriteByte('S')
+				i += 3
+			case at(i+1) == 'H':
+				p.WriteByte('X')
+				a.WriteByte('X')
+				i += 2
+			default:
+				p.WriteByte('S')
+				a.WriteByte('S')
+				i++
+			}
+		case 'T':
+			if at(i+1) == 'H' {
+				p.WriteByte('0')
+				a.WriteByte('T')
+				i += 2
+			} else {
+				p.WriteByte('T')
+				a.WriteByte('T')
+				i++
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				p.WriteByte('F')
+				a.WriteByte('F')
+				i += 2
+			} else {
+				p.WriteByte('P')
+				a.WriteByte('P')
+				i++
+			}
+		case 'G':
+			switch {
+			case at(i+1) == 'H':
+				i += 2 // silent in most English names (e.g. "Knight")
+			case at(i+1) == 'I' || at(i+1) == 'E' || at(i+1) == 'Y':
+				p.WriteByte('J')
+				a.WriteByte('J')
+				i++
+			default:
+				p.WriteByte('K')
+				a.WriteByte('K')
+				i++
+			}
+		case 'D':
+			if at(i+1) == 'G' && (at(i+2) == 'E' || at(i+2) == 'I' || at(i+2) == 'Y') {
+				p.WriteByte('J')
+				a.WriteByte('J')
+				i += 2
+			} else {
+				p.WriteByte('T')
+				a.WriteByte('T')
+				i++
+			}
+		case 'V':
+			p.WriteByte('F')
+			a.WriteByte('F')
+			i++
+		case 'W':
+			if isVowelLetter(at(i + 1)) {
+				p.WriteByte('W')
+				a.WriteByte('W')
+			}
+			i++
+		case 'X':
+			p.WriteString("KS")
+			a.WriteString("KS")
+			i++
+		case 'Z':
+			p.WriteByte('S')
+			a.WriteByte('S')
+			i++
+		case 'Q':
+			p.WriteByte('K')
+			a.WriteByte('K')
+			i++
+		case 'B', 'F', 'J', 'K', 'L', 'M', 'N', 'R':
+			p.WriteByte(c)
+			a.WriteByte(c)
+			i++
+		default:
+			i++
+		}
+	}
+
+	primary = collapseRuns(p.String())
+	alternate = collapseRuns(a.String())
+	if len(primary) > dmetaCodeLength {
+		primary = primary[:dmetaCodeLength]
+	}
+	if len(alternate) > dmetaCodeLength {
+		alternate = alternate[:dmetaCodeLength]
+	}
+	return primary, alternate
+}
+
+// collapseRuns collapses consecutive identical characters (e.g. the
+// "TT" left behind when a silent/merged letter and the next real
+// letter both code to the same symbol) into a single character.
+func collapseRuns(s string) string {
+	if s == "" {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte(s[0])
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[i-1] {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
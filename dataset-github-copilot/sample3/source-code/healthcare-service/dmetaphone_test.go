@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestDoubleMetaphone_CanonicalPairs(t *testing.T) {
+	pairs := []struct {
+		a, b string
+	}{
+		{"Smyth", "Smith"},
+		{"Jon", "John"},
+		{"Catherine", "Katherine"},
+		{"Müller", "Mueller"},
+	}
+
+	for _, pair := range pairs {
+		primaryA, altA := doubleMetaphone(pair.a)
+		primaryB, altB := doubleMetaphone(pair.b)
+		if primaryA != primaryB || altA != altB {
+			t.Errorf("doubleMetaphone(%q) = (%q, %q), doubleMetaphone(%q) = (%q, %q), want matching codes",
+				pair.a, primaryA, altA, pair.b, primaryB, altB)
+		}
+	}
+}
+
+func TestDoubleMetaphone_Schmidt(t *testing.T) {
+	primary, alt := doubleMetaphone("Schmidt")
+	if primary != "XMT" {
+		t.Errorf("primary = %q, want %q", primary, "XMT")
+	}
+	if alt != "SMT" {
+		t.Errorf("alternate = %q, want %q", alt, "SMT")
+	}
+}
+
+func TestDoubleMetaphone_Empty(t *testing.T) {
+	primary, alt := doubleMetaphone("")
+	if primary != "" || alt != "" {
+		t.Errorf("doubleMetaphone(\"\") = (%q, %q), want (\"\", \"\")", primary, alt)
+	}
+}
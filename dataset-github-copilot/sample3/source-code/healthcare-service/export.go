@@ -0,0 +1,468 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportOutputDir is where NDJSON export files are written. It's a
+// plain directory on local disk for now; swapping in object storage
+// would only mean changing exportResourceType, writeNDJSONLine and the
+// download handler.
+const exportOutputDir = "export-output"
+
+// exportDownloadTokenLifetime bounds how long a signed download URL for
+// an export file stays valid.
+const exportDownloadTokenLifetime = 15 * time.Minute
+
+// exportableResourceTypes are the FHIR resource types $export can
+// stream.
+var exportableResourceTypes = map[string]bool{
+	"Patient":             true,
+	"Observation":         true,
+	"AllergyIntolerance":  true,
+	"MedicationStatement": true,
+}
+
+// ExportJob tracks one FHIR Bulk Data $export request from kickoff
+// through completion. OutputFiles is populated only once Status is
+// "completed", and holds the JSON-encoded []exportOutputFile produced
+// by runExportJob.
+type ExportJob struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	RequestedBy   string     `json:"requested_by"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null"` // in-progress, completed, failed
+	ResourceTypes string     `json:"resource_types"`                          // comma-separated
+	Since         *time.Time `json:"since"`
+	Progress      int        `json:"progress"` // 0-100, resource types completed so far
+	OutputFiles   string     `json:"-" gorm:"type:text"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
+
+// exportOutputFile is one entry of an ExportJob's OutputFiles, and also
+// the shape of the "output" array in the completed-job manifest.
+type exportOutputFile struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+	path  string
+}
+
+type exportRequest struct {
+	ResourceTypes []string `json:"resource_types"`
+	Since         string   `json:"since"` // RFC3339, optional
+}
+
+// HandleBulkExport handles POST /api/v1/patients/$export. It records
+// the job, returns 202 immediately, and streams the requested resource
+// types to disk in the background - a real cohort can be large enough
+// that doing this synchronously would time out the request.
+func (hs *HealthcareService) HandleBulkExport(c *gin.Context) {
+	var req exportRequest
+	// The FHIR Bulk Data spec allows an empty body (meaning "every
+	// supported resource type"), so a parse failure on an empty body is
+	// not an error.
+	_ = c.ShouldBindJSON(&req)
+
+	resourceTypes := req.ResourceTypes
+	if len(resourceTypes) == 0 {
+		for resourceType := range exportableResourceTypes {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+	for _, resourceType := range resourceTypes {
+		if !exportableResourceTypes[resourceType] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported resource type", "resource_type": resourceType})
+			return
+		}
+	}
+
+	var since *time.Time
+	if req.Since != "" {
+		parsed, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since timestamp, expected RFC3339"})
+			return
+		}
+		since = &parsed
+	}
+
+	job := ExportJob{
+		ID:            uuid.New().String(),
+		RequestedBy:   c.GetString("user_id"),
+		Status:        "in-progress",
+		ResourceTypes: strings.Join(resourceTypes, ","),
+		Since:         since,
+		CreatedAt:     time.Now(),
+	}
+	if err := hs.db.Create(&job).Error; err != nil {
+		log.Printf("Error creating export job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start export job"})
+		return
+	}
+
+	go hs.runExportJob(job.ID, resourceTypes, since)
+
+	c.Header("Content-Location", fmt.Sprintf("/api/v1/export-status/%s", job.ID))
+	c.JSON(http.StatusAccepted, gin.H{"message": "export started", "job_id": job.ID})
+}
+
+// runExportJob streams each requested resource type to its own NDJSON
+// file under exportOutputDir/<jobID>/, one row at a time via hs.db's
+// Rows() cursor so a large cohort never has to be fully materialized
+// (or fully decrypted) in memory at once.
+func (hs *HealthcareService) runExportJob(jobID string, resourceTypes []string, since *time.Time) {
+	jobDir := filepath.Join(exportOutputDir, jobID)
+	if err := os.MkdirAll(jobDir, 0o700); err != nil {
+		hs.failExportJob(jobID, fmt.Errorf("create export directory: %w", err))
+		return
+	}
+
+	var outputs []exportOutputFile
+	for i, resourceType := range resourceTypes {
+		output, err := hs.exportResourceType(jobID, jobDir, resourceType, since)
+		if err != nil {
+			hs.failExportJob(jobID, fmt.Errorf("export %s: %w", resourceType, err))
+			return
+		}
+		outputs = append(outputs, output)
+		hs.db.Model(&ExportJob{}).Where("id = ?", jobID).
+			Update("progress", (i+1)*100/len(resourceTypes))
+	}
+
+	outputsJSON, err := json.Marshal(outputs)
+	if err != nil {
+		hs.failExportJob(jobID, fmt.Errorf("marshal output manifest: %w", err))
+		return
+	}
+
+	now := time.Now()
+	hs.db.Model(&ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":       "completed",
+		"progress":     100,
+		"output_files": string(outputsJSON),
+		"completed_at": now,
+	})
+}
+
+func (hs *HealthcareService) failExportJob(jobID string, cause error) {
+	log.Printf("Export job %s failed: %v", jobID, cause)
+	hs.db.Model(&ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status": "failed",
+		"error":  cause.Error(),
+	})
+}
+
+// exportResourceType streams one resource type's NDJSON file and
+// returns its output manifest entry. Each written resource is
+// audit-logged with the job ID and the resource's own ID.
+func (hs *HealthcareService) exportResourceType(jobID, jobDir, resourceType string, since *time.Time) (exportOutputFile, error) {
+	path := filepath.Join(jobDir, resourceType+".ndjson")
+	file, err := os.Create(path)
+	if err != nil {
+		return exportOutputFile{}, fmt.Errorf("create output file: %w", err)
+	}
+	defer file.Close()
+
+	var count int
+	switch resourceType {
+	case "Patient":
+		count, err = hs.streamPatientResources(jobID, file, since)
+	case "Observation":
+		count, err = hs.streamObservationResources(jobID, file, since)
+	case "AllergyIntolerance":
+		count, err = hs.streamAllergyResources(jobID, file, since)
+	case "MedicationStatement":
+		count, err = hs.streamMedicationResources(jobID, file, since)
+	default:
+		err = fmt.Errorf("unknown resource type %q", resourceType)
+	}
+	if err != nil {
+		return exportOutputFile{}, err
+	}
+
+	return exportOutputFile{Type: resourceType, Count: count, path: path}, nil
+}
+
+func writeNDJSONLine(file *os.File, resource interface{}) error {
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(encoded, '\n'))
+	return err
+}
+
+func (hs *HealthcareService) streamPatientResources(jobID string, file *os.File, since *time.Time) (int, error) {
+	query := hs.db.Model(&Patient{})
+	if since != nil {
+		query = query.Where("updated_at >= ?", *since)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var patient Patient
+		if err := hs.db.ScanRows(rows, &patient); err != nil {
+			return count, err
+		}
+
+		resource := map[string]interface{}{
+			"resourceType": "Patient",
+			"id":           patient.ID,
+			"name":         []map[string]interface{}{{"family": patient.LastName, "given": []string{patient.FirstName}}},
+			"gender":       patient.Gender,
+			"birthDate":    patient.DateOfBirth.Format("2006-01-02"),
+			"telecom":      hs.patientTelecom(&patient),
+			"address":      hs.patientAddress(&patient),
+		}
+		if err := writeNDJSONLine(file, resource); err != nil {
+			return count, err
+		}
+		hs.auditExportedResource(jobID, "Patient", patient.ID)
+		count++
+	}
+	return count, rows.Err()
+}
+
+// patientTelecom decrypts phone/email just long enough to build this
+// one resource's telecom entries; nothing decrypted here outlives this
+// call.
+func (hs *HealthcareService) patientTelecom(p *Patient) []map[string]interface{} {
+	var telecom []map[string]interface{}
+	if phone, err := hs.decryptString(p.PhoneNumber); err == nil && phone != "" {
+		telecom = append(telecom, map[string]interface{}{"system": "phone", "value": phone})
+	}
+	if email, err := hs.decryptString(p.Email); err == nil && email != "" {
+		telecom = append(telecom, map[string]interface{}{"system": "email", "value": email})
+	}
+	return telecom
+}
+
+func (hs *HealthcareService) patientAddress(p *Patient) []map[string]interface{} {
+	address, err := hs.decryptString(p.Address)
+	if err != nil || address == "" {
+		return nil
+	}
+	return []map[string]interface{}{{"text": address}}
+}
+
+func (hs *HealthcareService) streamObservationResources(jobID string, file *os.File, since *time.Time) (int, error) {
+	query := hs.db.Model(&VitalSigns{})
+	if since != nil {
+		query = query.Where("measured_at >= ?", *since)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var vitals VitalSigns
+		if err := hs.db.ScanRows(rows, &vitals); err != nil {
+			return count, err
+		}
+
+		for _, observation := range buildObservations(vitals.PatientID, &vitals, nil) {
+			if err := writeNDJSONLine(file, observation); err != nil {
+				return count, err
+			}
+			hs.auditExportedResource(jobID, "Observation", observation.ID)
+			count++
+		}
+	}
+	return count, rows.Err()
+}
+
+func (hs *HealthcareService) streamAllergyResources(jobID string, file *os.File, since *time.Time) (int, error) {
+	query := hs.db.Model(&Allergy{})
+	if since != nil {
+		query = query.Where("updated_at >= ?", *since)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var allergy Allergy
+		if err := hs.db.ScanRows(rows, &allergy); err != nil {
+			return count, err
+		}
+
+		resource := map[string]interface{}{
+			"resourceType": "AllergyIntolerance",
+			"id":           allergy.ID,
+			"patient":      fhirReference{Reference: "Patient/" + allergy.PatientID},
+			"code":         fhirCodeableConcept{Text: allergy.Allergen},
+			"reaction": []map[string]interface{}{{
+				"description": allergy.Reaction,
+				"severity":    strings.ToLower(allergy.Severity),
+			}},
+		}
+		if err := writeNDJSONLine(file, resource); err != nil {
+			return count, err
+		}
+		hs.auditExportedResource(jobID, "AllergyIntolerance", allergy.ID)
+		count++
+	}
+	return count, rows.Err()
+}
+
+func (hs *HealthcareService) streamMedicationResources(jobID string, file *os.File, since *time.Time) (int, error) {
+	query := hs.db.Model(&Medication{})
+	if since != nil {
+		query = query.Where("updated_at >= ?", *since)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var medication Medication
+		if err := hs.db.ScanRows(rows, &medication); err != nil {
+			return count, err
+		}
+
+		status := "active"
+		if !medication.IsActive {
+			status = "stopped"
+		}
+		resource := map[string]interface{}{
+			"resourceType": "MedicationStatement",
+			"id":           medication.ID,
+			"status":       status,
+			"subject":      fhirReference{Reference: "Patient/" + medication.PatientID},
+			"medicationCodeableConcept": fhirCodeableConcept{Text: medication.Name},
+			"dosage": []map[string]interface{}{{
+				"text": strings.TrimSpace(medication.Dosage + " " + medication.Frequency),
+			}},
+		}
+		if err := writeNDJSONLine(file, resource); err != nil {
+			return count, err
+		}
+		hs.auditExportedResource(jobID, "MedicationStatement", medication.ID)
+		count++
+	}
+	return count, rows.Err()
+}
+
+func (hs *HealthcareService) auditExportedResource(jobID, resourceType, resourceID string) {
+	hs.auditLogger.LogEvent(map[string]interface{}{
+		"action":        "bulk_export_resource",
+		"job_id":        jobID,
+		"resource_type": resourceType,
+		"resource_id":   resourceID,
+		"timestamp":     time.Now(),
+	})
+}
+
+// HandleExportStatus handles GET /api/v1/export-status/:jobId: 202 with
+// X-Progress while the job runs, then 200 with the Bulk Data manifest
+// once it's done.
+func (hs *HealthcareService) HandleExportStatus(c *gin.Context) {
+	jobID := c.Param("jobId")
+
+	var job ExportJob
+	if err := hs.db.First(&job, "id = ?", jobID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export job not found"})
+		return
+	}
+
+	switch job.Status {
+	case "failed":
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "export job failed", "details": job.Error})
+		return
+	case "completed":
+		var outputs []exportOutputFile
+		if err := json.Unmarshal([]byte(job.OutputFiles), &outputs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read export manifest"})
+			return
+		}
+		for i := range outputs {
+			outputs[i].URL = hs.signedExportDownloadURL(job.ID, outputs[i].Type+".ndjson")
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"transactionTime":     job.CreatedAt.Format(time.RFC3339),
+			"request":             "/api/v1/patients/$export",
+			"requiresAccessToken": true,
+			"output":              outputs,
+		})
+	default:
+		c.Header("X-Progress", strconv.Itoa(job.Progress)+"%")
+		c.JSON(http.StatusAccepted, gin.H{"status": job.Status, "progress": job.Progress})
+	}
+}
+
+// signedExportDownloadURL builds a short-lived signed URL for an export
+// output file, verified by HandleExportDownload.
+func (hs *HealthcareService) signedExportDownloadURL(jobID, filename string) string {
+	expires := time.Now().Add(exportDownloadTokenLifetime).Unix()
+	token := hs.exportDownloadToken(jobID, filename, expires)
+	return fmt.Sprintf("/api/v1/export-download/%s/%s?expires=%d&token=%s", jobID, filename, expires, token)
+}
+
+func (hs *HealthcareService) exportDownloadToken(jobID, filename string, expires int64) string {
+	mac := hmac.New(sha256.New, hs.encryptionKey)
+	fmt.Fprintf(mac, "%s:%s:%d", jobID, filename, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HandleExportDownload handles GET
+// /api/v1/export-download/:jobId/:filename, serving an export NDJSON
+// file after verifying its expires/token query params.
+func (hs *HealthcareService) HandleExportDownload(c *gin.Context) {
+	jobID := c.Param("jobId")
+	filename := c.Param("filename")
+
+	expiresParam := c.Query("expires")
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expires parameter"})
+		return
+	}
+	if time.Now().Unix() > expires {
+		c.JSON(http.StatusForbidden, gin.H{"error": "download link expired"})
+		return
+	}
+
+	expectedToken := hs.exportDownloadToken(jobID, filename, expires)
+	if !hmac.Equal([]byte(expectedToken), []byte(c.Query("token"))) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid download token"})
+		return
+	}
+
+	path := filepath.Join(exportOutputDir, jobID, filepath.Base(filename))
+	c.FileAttachment(path, filename)
+}
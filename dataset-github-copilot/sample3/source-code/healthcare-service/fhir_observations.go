@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// vitalObservationSpec describes how a single VitalSigns measurement maps
+// onto a FHIR Observation: its LOINC code/display, UCUM unit, and how to
+// read the value (and whether it was recorded) off a VitalSigns row.
+type vitalObservationSpec struct {
+	loincCode    string
+	loincDisplay string
+	ucumUnit     string
+	ucumCode     string
+	value        func(vs *VitalSigns) (float64, bool)
+}
+
+// vitalObservationSpecs enumerates the vitals RecordVitalSigns captures,
+// in the LOINC codes FHIR consumers expect.
+var vitalObservationSpecs = []vitalObservationSpec{
+	{"8310-5", "Body temperature", "degree Celsius", "Cel", func(vs *VitalSigns) (float64, bool) { return vs.Temperature, vs.Temperature != 0 }},
+	{"8480-6", "Systolic blood pressure", "mm[Hg]", "mm[Hg]", func(vs *VitalSigns) (float64, bool) { return float64(vs.BloodPressureSys), vs.BloodPressureSys != 0 }},
+	{"8462-4", "Diastolic blood pressure", "mm[Hg]", "mm[Hg]", func(vs *VitalSigns) (float64, bool) { return float64(vs.BloodPressureDia), vs.BloodPressureDia != 0 }},
+	{"8867-4", "Heart rate", "beats/minute", "/min", func(vs *VitalSigns) (float64, bool) { return float64(vs.HeartRate), vs.HeartRate != 0 }},
+	{"9279-1", "Respiratory rate", "breaths/minute", "/min", func(vs *VitalSigns) (float64, bool) { return float64(vs.RespiratoryRate), vs.RespiratoryRate != 0 }},
+	{"2708-6", "Oxygen saturation", "percent", "%", func(vs *VitalSigns) (float64, bool) { return vs.OxygenSaturation, vs.OxygenSaturation != 0 }},
+	{"8302-2", "Body height", "centimeter", "cm", func(vs *VitalSigns) (float64, bool) { return vs.Height, vs.Height > 0 }},
+	{"29463-7", "Body weight", "kilogram", "kg", func(vs *VitalSigns) (float64, bool) { return vs.Weight, vs.Weight > 0 }},
+	{"39156-5", "Body mass index (BMI)", "kilogram per square meter", "kg/m2", func(vs *VitalSigns) (float64, bool) { return vs.BMI, vs.BMI > 0 }},
+	{"38208-5", "Pain severity", "{score}", "{score}", func(vs *VitalSigns) (float64, bool) { return float64(vs.PainScale), vs.PainScale != 0 }},
+}
+
+// fhirInterpretationForLOINC maps the free-text alerts produced by
+// validateVitalSignsRanges onto the FHIR interpretation codes (H/L/HH/LL)
+// for the LOINC code that alert concerns. Alerts that don't name a vital
+// covered by vitalObservationSpecs are ignored.
+func fhirInterpretationForLOINC(loincCode string, alerts []string) string {
+	var wantsHigh, wantsLow, wantsCritical bool
+	for _, alert := range alerts {
+		switch loincCode {
+		case "8480-6", "8462-4":
+			if strings.Contains(alert, "Hypertensive crisis") {
+				wantsHigh, wantsCritical = true, true
+			} else if strings.Contains(alert, "Elevated blood pressure") {
+				wantsHigh = true
+			}
+		case "8867-4":
+			if strings.Contains(alert, "Tachycardia") {
+				wantsHigh = true
+			} else if strings.Contains(alert, "Bradycardia") {
+				wantsLow = true
+			}
+		case "2708-6":
+			if strings.Contains(alert, "Severe hypoxemia") {
+				wantsLow, wantsCritical = true, true
+			} else if strings.Contains(alert, "Mild hypoxemia") {
+				wantsLow = true
+			}
+		case "8310-5":
+			if strings.Contains(alert, "High fever") {
+				wantsHigh = true
+			} else if strings.Contains(alert, "Hypothermia") {
+				wantsLow = true
+			}
+		}
+	}
+
+	switch {
+	case wantsHigh && wantsCritical:
+		return "HH"
+	case wantsLow && wantsCritical:
+		return "LL"
+	case wantsHigh:
+		return "H"
+	case wantsLow:
+		return "L"
+	default:
+		return ""
+	}
+}
+
+// FHIRObservation is the subset of the FHIR R4 Observation resource this
+// service populates. Fields left unset (e.g. a missing interpretation)
+// are omitted so round-trip parsing tolerates optional fields.
+type FHIRObservation struct {
+	ResourceType   string                     `json:"resourceType"`
+	ID             string                     `json:"id,omitempty"`
+	Status         string                     `json:"status"`
+	Category       []fhirCodeableConcept      `json:"category,omitempty"`
+	Code           fhirCodeableConcept        `json:"code"`
+	Subject        fhirReference              `json:"subject"`
+	EffectiveDateTime string                  `json:"effectiveDateTime"`
+	ValueQuantity  *fhirQuantity              `json:"valueQuantity,omitempty"`
+	Interpretation []fhirCodeableConcept      `json:"interpretation,omitempty"`
+}
+
+type fhirCodeableConcept struct {
+	Coding []fhirCoding `json:"coding,omitempty"`
+	Text   string       `json:"text,omitempty"`
+}
+
+type fhirCoding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type fhirReference struct {
+	Reference string `json:"reference"`
+}
+
+type fhirQuantity struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	System string  `json:"system,omitempty"`
+	Code   string  `json:"code,omitempty"`
+}
+
+// CreatePatientResource POSTs a minimal FHIR Patient resource for p to
+// the FHIR server, so downstream Observation resources have a Patient to
+// reference.
+func (fc *FHIRClient) CreatePatientResource(p *Patient) error {
+	resource := map[string]interface{}{
+		"resourceType": "Patient",
+		"id":           p.ID,
+		"name": []map[string]interface{}{{
+			"family": p.LastName,
+			"given":  []string{p.FirstName},
+		}},
+		"birthDate": p.DateOfBirth.Format("2006-01-02"),
+	}
+
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("marshal patient resource: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fc.baseURL+"/Patient", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build patient resource request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/fhir+json")
+	if fc.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+fc.apiKey)
+	}
+
+	resp, err := fc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post patient resource: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("FHIR server rejected patient resource with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// buildObservations translates a single VitalSigns measurement into the
+// FHIR Observation resources it covers, skipping any vital that wasn't
+// recorded (zero-valued). alerts are the strings returned by
+// validateVitalSignsRanges, used to populate Observation.interpretation.
+func buildObservations(patientID string, vs *VitalSigns, alerts []string) []FHIRObservation {
+	var observations []FHIRObservation
+	for _, spec := range vitalObservationSpecs {
+		value, present := spec.value(vs)
+		if !present {
+			continue
+		}
+
+		obs := FHIRObservation{
+			ResourceType: "Observation",
+			ID:           uuid.New().String(),
+			Status:       "final",
+			Category: []fhirCodeableConcept{{
+				Coding: []fhirCoding{{System: "http://terminology.hl7.org/CodeSystem/observation-category", Code: "vital-signs", Display: "Vital Signs"}},
+			}},
+			Code: fhirCodeableConcept{
+				Coding: []fhirCoding{{System: "http://loinc.org", Code: spec.loincCode, Display: spec.loincDisplay}},
+			},
+			Subject:           fhirReference{Reference: "Patient/" + patientID},
+			EffectiveDateTime: vs.MeasuredAt.Format(time.RFC3339),
+			ValueQuantity: &fhirQuantity{
+				Value:  value,
+				Unit:   spec.ucumUnit,
+				System: "http://unitsofmeasure.org",
+				Code:   spec.ucumCode,
+			},
+		}
+
+		if interpretation := fhirInterpretationForLOINC(spec.loincCode, alerts); interpretation != "" {
+			obs.Interpretation = []fhirCodeableConcept{{
+				Coding: []fhirCoding{{System: "http://terminology.hl7.org/CodeSystem/v3-ObservationInterpretation", Code: interpretation}},
+			}}
+		}
+
+		observations = append(observations, obs)
+	}
+	return observations
+}
+
+// CreateObservationResources POSTs one FHIR Observation per measured
+// vital in vs to the FHIR server, so external systems can query a
+// patient's vitals history via the standard FHIR Observation search API.
+func (fc *FHIRClient) CreateObservationResources(patientID string, vs *VitalSigns, alerts []string) error {
+	for _, obs := range buildObservations(patientID, vs, alerts) {
+		body, err := json.Marshal(obs)
+		if err != nil {
+			return fmt.Errorf("marshal observation: %w", err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, fc.baseURL+"/Observation", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("build observation request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/fhir+json")
+		if fc.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+fc.apiKey)
+		}
+
+		resp, err := fc.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("post observation %s: %w", obs.Code.Coding[0].Code, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("FHIR server rejected observation %s with status %d", obs.Code.Coding[0].Code, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// fhirBundle is the minimal shape of a FHIR searchset Bundle this client
+// needs to read back Observation search results.
+type fhirBundle struct {
+	Entry []struct {
+		Resource FHIRObservation `json:"resource"`
+	} `json:"entry"`
+}
+
+// QueryObservations searches the FHIR server for Observations of the
+// given LOINC code for patientID, optionally constrained to
+// effectiveDateTime after sinceRFC3339, and returns them oldest-first.
+func (fc *FHIRClient) QueryObservations(patientID, loincCode, sinceRFC3339 string) ([]FHIRObservation, error) {
+	query := url.Values{}
+	query.Set("patient", patientID)
+	if loincCode != "" {
+		query.Set("code", "http://loinc.org|"+loincCode)
+	}
+	if sinceRFC3339 != "" {
+		query.Add("date", "gt"+sinceRFC3339)
+	}
+	query.Set("_sort", "date")
+
+	req, err := http.NewRequest(http.MethodGet, fc.baseURL+"/Observation?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build observation query: %w", err)
+	}
+	req.Header.Set("Accept", "application/fhir+json")
+	if fc.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+fc.apiKey)
+	}
+
+	resp, err := fc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query observations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("FHIR server returned status %d querying observations", resp.StatusCode)
+	}
+
+	var bundle fhirBundle
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("decode observation bundle: %w", err)
+	}
+
+	observations := make([]FHIRObservation, 0, len(bundle.Entry))
+	for _, entry := range bundle.Entry {
+		observations = append(observations, entry.Resource)
+	}
+	return observations, nil
+}
+
+// GetPatientObservations handles GET /api/v1/patients/:id/observations,
+// returning a specific vital's history from the FHIR server so the UI
+// can chart trends. The vital is selected by its LOINC code via the
+// ?code= query param; ?since= (RFC3339) limits how far back to look.
+func (hs *HealthcareService) GetPatientObservations(c *gin.Context) {
+	patientID := c.Param("id")
+	if _, err := uuid.Parse(patientID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID format"})
+		return
+	}
+
+	loincCode := c.Query("code")
+	if loincCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code query parameter (LOINC) is required"})
+		return
+	}
+
+	observations, err := hs.fhirClient.QueryObservations(patientID, loincCode, c.Query("since"))
+	if err != nil {
+		log.Printf("Error querying FHIR observations: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to query observation history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"patient_id":   patientID,
+		"code":         loincCode,
+		"observations": observations,
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// patientSearchResult is a Patient plus the composite match_score
+// SearchPatients computes for it when fuzzy=true. MatchScore is zero
+// (and omitted) for exact, non-fuzzy matches.
+type patientSearchResult struct {
+	Patient
+	MatchScore float64 `json:"match_score,omitempty"`
+}
+
+// nameFuzzyTerm holds the per-field SQL fragments fuzzySearchPatients
+// combines into the overall composite score and WHERE filter.
+type nameFuzzyTerm struct {
+	scoreExpr  string
+	scoreArgs  []interface{}
+	filterExpr string
+	filterArgs []interface{}
+}
+
+// buildNameFuzzyTerm builds the trigram-similarity-OR-phonetic-match
+// fragments for one name column, e.g. column="last_name". The score
+// expression is 0.6*trigram_similarity + 0.4*phonetic_match (1 or 0);
+// the filter expression is true when either signal is strong enough to
+// consider the row a candidate at all.
+func buildNameFuzzyTerm(column, dmetaPrimaryColumn, dmetaAltColumn, value string) nameFuzzyTerm {
+	primary, alt := doubleMetaphone(value)
+
+	return nameFuzzyTerm{
+		scoreExpr: fmt.Sprintf(
+			"(0.6 * COALESCE(similarity(%s, ?), 0) + 0.4 * (CASE WHEN %s IN (?, ?) OR %s IN (?, ?) THEN 1 ELSE 0 END))",
+			column, dmetaPrimaryColumn, dmetaAltColumn),
+		scoreArgs: []interface{}{value, primary, alt, primary, alt},
+		filterExpr: fmt.Sprintf(
+			"(similarity(%s, ?) > 0.3 OR %s IN (?, ?) OR %s IN (?, ?))",
+			column, dmetaPrimaryColumn, dmetaAltColumn),
+		filterArgs: []interface{}{value, primary, alt, primary, alt},
+	}
+}
+
+// fuzzySearchPatients runs SearchPatients' fuzzy=true path: a trigram +
+// Double Metaphone match over whichever of firstName/lastName was
+// given, scored 0.6*trigram + 0.4*phonetic (averaged across the name
+// fields provided) + 0.1*dob_match, filtered to rows scoring at least
+// minScore, and ordered best-match-first. medicalRecordNumber/phone/
+// email still apply as hard filters, same as the exact-match path.
+func (hs *HealthcareService) fuzzySearchPatients(
+	firstName, lastName, medicalRecordNumber, phoneNumber, email string,
+	dateOfBirth *time.Time, minScore float64, limit, offset int,
+) ([]patientSearchResult, int64, error) {
+	var terms []nameFuzzyTerm
+	if lastName != "" {
+		terms = append(terms, buildNameFuzzyTerm("last_name", "last_name_dmeta_primary", "last_name_dmeta_alt", lastName))
+	}
+	if firstName != "" {
+		terms = append(terms, buildNameFuzzyTerm("first_name", "first_name_dmeta_primary", "first_name_dmeta_alt", firstName))
+	}
+	if len(terms) == 0 {
+		return nil, 0, fmt.Errorf("fuzzy search requires first_name and/or last_name")
+	}
+
+	var scoreParts, filterParts []string
+	var scoreArgs, filterArgs []interface{}
+	for _, term := range terms {
+		scoreParts = append(scoreParts, term.scoreExpr)
+		scoreArgs = append(scoreArgs, term.scoreArgs...)
+		filterParts = append(filterParts, term.filterExpr)
+		filterArgs = append(filterArgs, term.filterArgs...)
+	}
+
+	nameScoreExpr := fmt.Sprintf("((%s) / %d)", strings.Join(scoreParts, ") + ("), len(scoreParts))
+
+	dobScoreExpr := "0"
+	var dobArgs []interface{}
+	if dateOfBirth != nil {
+		dobScoreExpr = "(CASE WHEN date_of_birth = ? THEN 1 ELSE 0 END)"
+		dobArgs = append(dobArgs, *dateOfBirth)
+	}
+
+	scoreExpr := fmt.Sprintf("(%s + 0.1 * %s)", nameScoreExpr, dobScoreExpr)
+
+	var innerSQL strings.Builder
+	var innerArgs []interface{}
+	innerSQL.WriteString("SELECT patients.*, ")
+	innerSQL.WriteString(scoreExpr)
+	innerArgs = append(innerArgs, scoreArgs...)
+	innerArgs = append(innerArgs, dobArgs...)
+	innerSQL.WriteString(" AS match_score FROM patients WHERE is_active = true")
+
+	if medicalRecordNumber != "" {
+		innerSQL.WriteString(" AND medical_record_number = ?")
+		innerArgs = append(innerArgs, medicalRecordNumber)
+	}
+	if phoneNumber != "" {
+		cleanPhone := strings.ReplaceAll(phoneNumber, "[^0-9]", "")
+		innerSQL.WriteString(" AND REGEXP_REPLACE(phone_number, '[^0-9]', '', 'g') LIKE ?")
+		innerArgs = append(innerArgs, "%"+cleanPhone+"%")
+	}
+	if email != "" {
+		innerSQL.WriteString(" AND LOWER(email) LIKE LOWER(?)")
+		innerArgs = append(innerArgs, "%"+email+"%")
+	}
+	innerSQL.WriteString(" AND (" + strings.Join(filterParts, " OR ") + ")")
+	innerArgs = append(innerArgs, filterArgs...)
+
+	var totalCount int64
+	countArgs := append(append([]interface{}{}, innerArgs...), minScore)
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM (%s) matched WHERE matched.match_score >= ?", innerSQL.String())
+	if err := hs.db.Raw(countSQL, countArgs...).Scan(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("count fuzzy matches: %w", err)
+	}
+
+	var results []patientSearchResult
+	pageArgs := append(append([]interface{}{}, innerArgs...), minScore, limit, offset)
+	pageSQL := fmt.Sprintf(
+		"SELECT * FROM (%s) matched WHERE matched.match_score >= ? ORDER BY matched.match_score DESC LIMIT ? OFFSET ?",
+		innerSQL.String())
+	if err := hs.db.Raw(pageSQL, pageArgs...).Scan(&results).Error; err != nil {
+		return nil, 0, fmt.Errorf("run fuzzy search: %w", err)
+	}
+
+	return results, totalCount, nil
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,8 +20,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+
+	"healthcare-service/tlsauth"
 )
 
 // Patient represents a patient in the healthcare system with comprehensive
@@ -45,7 +47,17 @@ type Patient struct {
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 	LastVisit        *time.Time `json:"last_visit"`
-	
+
+	// Precomputed Double Metaphone codes for fuzzy name search (see
+	// dmetaphone.go and SearchPatients' fuzzy=true path). Kept up to
+	// date by the BeforeSave hook below rather than computed at query
+	// time, so SearchPatients can match against them with a plain index
+	// lookup instead of recomputing phonetics for every row.
+	FirstNameDMetaPrimary string `json:"-" gorm:"column:first_name_dmeta_primary;type:varchar(4);index"`
+	FirstNameDMetaAlt     string `json:"-" gorm:"column:first_name_dmeta_alt;type:varchar(4);index"`
+	LastNameDMetaPrimary  string `json:"-" gorm:"column:last_name_dmeta_primary;type:varchar(4);index"`
+	LastNameDMetaAlt      string `json:"-" gorm:"column:last_name_dmeta_alt;type:varchar(4);index"`
+
 	// Related entities for comprehensive patient management
 	Allergies     []Allergy     `json:"allergies" gorm:"foreignKey:PatientID"`
 	Medications   []Medication  `json:"medications" gorm:"foreignKey:PatientID"`
@@ -54,6 +66,14 @@ type Patient struct {
 	LabResults    []LabResult   `json:"lab_results" gorm:"foreignKey:PatientID"`
 }
 
+// BeforeSave keeps the precomputed Double Metaphone columns in sync
+// whenever a patient's name changes.
+func (p *Patient) BeforeSave(tx *gorm.DB) error {
+	p.FirstNameDMetaPrimary, p.FirstNameDMetaAlt = doubleMetaphone(p.FirstName)
+	p.LastNameDMetaPrimary, p.LastNameDMetaAlt = doubleMetaphone(p.LastName)
+	return nil
+}
+
 // Allergy represents patient allergies with severity levels and reaction details.
 // Critical for clinical decision support and medication safety.
 type Allergy struct {
@@ -90,15 +110,63 @@ type VitalSigns struct {
 	CreatedAt       time.Time `json:"created_at"`
 }
 
+// Medication represents a medication a patient is currently prescribed or
+// has been prescribed historically. Used for clinical decision support
+// (drug interaction and allergy checks) and FHIR MedicationStatement export.
+type Medication struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	PatientID    string     `json:"patient_id" gorm:"not null;index"`
+	Name         string     `json:"name" gorm:"not null"`
+	Dosage       string     `json:"dosage" gorm:"type:varchar(100)"`
+	Frequency    string     `json:"frequency" gorm:"type:varchar(100)"`
+	PrescribedBy string     `json:"prescribed_by"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	IsActive     bool       `json:"is_active" gorm:"default:true"`
+	Notes        string     `json:"notes" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Appointment represents a scheduled patient visit.
+type Appointment struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	PatientID       string    `json:"patient_id" gorm:"not null;index"`
+	ProviderID      string    `json:"provider_id"`
+	AppointmentDate time.Time `json:"appointment_date" gorm:"not null;index"`
+	Reason          string    `json:"reason" gorm:"type:text"`
+	Status          string    `json:"status" gorm:"type:varchar(20)"` // SCHEDULED, COMPLETED, CANCELLED, NO_SHOW
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// LabResult represents a laboratory test result for a patient.
+type LabResult struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	PatientID  string    `json:"patient_id" gorm:"not null;index"`
+	TestName   string    `json:"test_name" gorm:"not null"`
+	TestCode   string    `json:"test_code"`
+	Value      string    `json:"value"`
+	Unit       string    `json:"unit"`
+	ReferenceRange string `json:"reference_range"`
+	Abnormal   bool      `json:"abnormal"`
+	ResultDate time.Time `json:"result_date" gorm:"not null;index"`
+	OrderedBy  string    `json:"ordered_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // HealthcareService provides comprehensive patient management capabilities
 // with HIPAA compliance, audit logging, and integration with external systems.
 // Implements advanced clinical workflows and decision support features.
 type HealthcareService struct {
-	db           *gorm.DB
+	db            *gorm.DB
+	dbHealth      *replicaHealthMonitor
+	writeTracker  *writeRecencyTracker
 	encryptionKey []byte
-	auditLogger  *AuditLogger
-	fhirClient   *FHIRClient
-	upgrader     websocket.Upgrader
+	auditLogger   *AuditLogger
+	fhirClient    *FHIRClient
+	upgrader      websocket.Upgrader
+	certManager   *tlsauth.Manager
 }
 
 // AuditLogger handles HIPAA-compliant audit logging for all patient data access
@@ -117,19 +185,52 @@ type FHIRClient struct {
 
 // NewHealthcareService creates a new healthcare service instance with
 // database connection, encryption setup, and external service clients.
-func NewHealthcareService(dbConn *gorm.DB, encryptionKey []byte) *HealthcareService {
+// certManager may be nil if mTLS-protected endpoints aren't in use.
+// NewHealthcareService opens dbConfig.PrimaryDSN plus any read replicas
+// and registers GORM's dbresolver plugin so read-heavy handlers (patient
+// lookups, searches, audit queries) spread load across replicas while
+// writes stay on the primary. See withReadConsistency for how handlers
+// opt back into primary reads when they need read-after-write
+// consistency for a given patient.
+func NewHealthcareService(dbConfig DBConfig, encryptionKey []byte, certManager *tlsauth.Manager) (*HealthcareService, error) {
+	dbConn, dbHealth, err := openDB(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &HealthcareService{
-		db:           dbConn,
+		db:            dbConn,
+		dbHealth:      dbHealth,
+		writeTracker:  newWriteRecencyTracker(1000),
 		encryptionKey: encryptionKey,
-		auditLogger:  &AuditLogger{db: dbConn},
-		fhirClient:   &FHIRClient{
+		auditLogger:   &AuditLogger{db: dbConn},
+		fhirClient: &FHIRClient{
 			baseURL: "https://api.fhir.org/R4",
 			client:  &http.Client{Timeout: 30 * time.Second},
 		},
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool { return true },
 		},
+		certManager: certManager,
+	}, nil
+}
+
+// HandleHealthz handles GET /healthz, reporting whether the primary
+// database is reachable and each read replica's availability and
+// replication lag.
+func (hs *HealthcareService) HandleHealthz(c *gin.Context) {
+	if hs.dbHealth == nil {
+		c.JSON(http.StatusOK, gin.H{"primary": true, "replicas": []replicaStatus{}})
+		return
 	}
+
+	primaryHealthy, replicas := hs.dbHealth.Status()
+
+	status := http.StatusOK
+	if !primaryHealthy {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"primary": primaryHealthy, "replicas": replicas})
 }
 
 // RegisterPatient creates a new patient record with comprehensive validation,
@@ -220,9 +321,10 @@ func (hs *HealthcareService) RegisterPatient(c *gin.Context) {
 			log.Printf("Error creating FHIR patient resource: %v", err)
 		}
 	}()
-	
+
 	tx.Commit()
-	
+	hs.recordWrite(patient.ID)
+
 	// Return patient data without sensitive information
 	sanitizedPatient := hs.sanitizePatientForResponse(&patient)
 	c.JSON(http.StatusCreated, gin.H{
@@ -254,10 +356,26 @@ func (hs *HealthcareService) GetPatientRecord(c *gin.Context) {
 		})
 		return
 	}
-	
+
+	// Medications and lab results may only be disclosed once the patient
+	// has signed the consent template covering them. Block the whole
+	// request (rather than silently omitting the sections) so the
+	// caller knows exactly which consent is outstanding.
+	if missing := hs.missingConsentSections(patientID); len(missing) > 0 {
+		for section, documentType := range missing {
+			c.JSON(http.StatusUnavailableForLegalReasons, gin.H{
+				"error":         "consent required",
+				"section":       section,
+				"document_type": documentType,
+				"message":       fmt.Sprintf("patient has no active %s consent on file", documentType),
+			})
+			return
+		}
+	}
+
 	// Retrieve patient with all related data
 	var patient Patient
-	result := hs.db.Preload("Allergies").
+	result := hs.withReadConsistency(c).Preload("Allergies").
 		Preload("Medications").
 		Preload("VitalSigns", func(db *gorm.DB) *gorm.DB {
 			return db.Order("measured_at DESC").Limit(10)
@@ -371,10 +489,20 @@ func (hs *HealthcareService) RecordVitalSigns(c *gin.Context) {
 	if len(alerts) > 0 {
 		go hs.processVitalSignsAlerts(alerts, &patient, &vitalSigns)
 	}
+
+	// Publish the measurement to the FHIR server as Observation resources
+	// so it can be queried by code (e.g. GetPatientObservations) or by
+	// other systems integrating over FHIR.
+	go func() {
+		if err := hs.fhirClient.CreateObservationResources(patientID, &vitalSigns, alerts); err != nil {
+			log.Printf("Error creating FHIR observation resources: %v", err)
+		}
+	}()
 	
 	// Update patient's last visit time
 	hs.db.Model(&patient).Update("last_visit", time.Now())
-	
+	hs.recordWrite(patientID)
+
 	// Log audit event
 	auditEvent := map[string]interface{}{
 		"action":         "vital_signs_recorded",
@@ -413,80 +541,108 @@ func (hs *HealthcareService) SearchPatients(c *gin.Context) {
 	medicalRecordNumber := c.Query("mrn")
 	phoneNumber := c.Query("phone")
 	email := c.Query("email")
-	
+	fuzzy := c.Query("fuzzy") == "true"
+	minScore, _ := strconv.ParseFloat(c.DefaultQuery("min_score", "0"), 64)
+
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	
+
 	// Validate search criteria
-	if firstName == "" && lastName == "" && dateOfBirth == "" && 
+	if firstName == "" && lastName == "" && dateOfBirth == "" &&
 	   medicalRecordNumber == "" && phoneNumber == "" && email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "At least one search criterion is required",
 		})
 		return
 	}
-	
-	// Build dynamic query based on search parameters
-	query := hs.db.Model(&Patient{}).Where("is_active = ?", true)
-	
-	if firstName != "" {
-		query = query.Where("LOWER(first_name) LIKE LOWER(?)", "%"+firstName+"%")
-	}
-	
-	if lastName != "" {
-		query = query.Where("LOWER(last_name) LIKE LOWER(?)", "%"+lastName+"%")
-	}
-	
-	if medicalRecordNumber != "" {
-		query = query.Where("medical_record_number = ?", medicalRecordNumber)
-	}
-	
-	if dateOfBirth != "" {
-		if parsedDate, err := time.Parse("2006-01-02", dateOfBirth); err == nil {
-			query = query.Where("date_of_birth = ?", parsedDate)
-		}
-	}
-	
-	if phoneNumber != "" {
-		// Remove non-numeric characters for phone search
-		cleanPhone := strings.ReplaceAll(phoneNumber, "[^0-9]", "")
-		query = query.Where("REGEXP_REPLACE(phone_number, '[^0-9]', '', 'g') LIKE ?", "%"+cleanPhone+"%")
-	}
-	
-	if email != "" {
-		query = query.Where("LOWER(email) LIKE LOWER(?)", "%"+email+"%")
-	}
-	
-	// Execute search with pagination
-	var patients []Patient
-	var totalCount int64
-	
-	// Get total count for pagination
-	query.Count(&totalCount)
-	
-	// Get paginated results
-	result := query.Limit(limit).Offset(offset).Order("last_name, first_name").Find(&patients)
-	
-	if result.Error != nil {
-		log.Printf("Error searching patients: %v", result.Error)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to search patients",
+
+	if fuzzy && firstName == "" && lastName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "fuzzy search requires first_name and/or last_name",
 		})
 		return
 	}
-	
+
+	var patients []patientSearchResult
+	var totalCount int64
+
+	if fuzzy {
+		var parsedDate *time.Time
+		if dateOfBirth != "" {
+			if d, err := time.Parse("2006-01-02", dateOfBirth); err == nil {
+				parsedDate = &d
+			}
+		}
+
+		results, count, err := hs.fuzzySearchPatients(
+			firstName, lastName, medicalRecordNumber, phoneNumber, email, parsedDate, minScore, limit, offset)
+		if err != nil {
+			log.Printf("Error running fuzzy patient search: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search patients"})
+			return
+		}
+		patients, totalCount = results, count
+	} else {
+		// Build dynamic query based on search parameters
+		query := hs.db.Model(&Patient{}).Where("is_active = ?", true)
+
+		if firstName != "" {
+			query = query.Where("LOWER(first_name) LIKE LOWER(?)", "%"+firstName+"%")
+		}
+
+		if lastName != "" {
+			query = query.Where("LOWER(last_name) LIKE LOWER(?)", "%"+lastName+"%")
+		}
+
+		if medicalRecordNumber != "" {
+			query = query.Where("medical_record_number = ?", medicalRecordNumber)
+		}
+
+		if dateOfBirth != "" {
+			if parsedDate, err := time.Parse("2006-01-02", dateOfBirth); err == nil {
+				query = query.Where("date_of_birth = ?", parsedDate)
+			}
+		}
+
+		if phoneNumber != "" {
+			// Remove non-numeric characters for phone search
+			cleanPhone := strings.ReplaceAll(phoneNumber, "[^0-9]", "")
+			query = query.Where("REGEXP_REPLACE(phone_number, '[^0-9]', '', 'g') LIKE ?", "%"+cleanPhone+"%")
+		}
+
+		if email != "" {
+			query = query.Where("LOWER(email) LIKE LOWER(?)", "%"+email+"%")
+		}
+
+		// Get total count for pagination
+		query.Count(&totalCount)
+
+		// Get paginated results
+		result := query.Limit(limit).Offset(offset).Order("last_name, first_name").Find(&patients)
+		if result.Error != nil {
+			log.Printf("Error searching patients: %v", result.Error)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to search patients",
+			})
+			return
+		}
+	}
+
 	// Decrypt and sanitize patient data for response
 	var sanitizedPatients []map[string]interface{}
 	for _, patient := range patients {
-		if err := hs.decryptSensitiveFields(&patient); err != nil {
+		if err := hs.decryptSensitiveFields(&patient.Patient); err != nil {
 			log.Printf("Error decrypting patient data: %v", err)
 			continue
 		}
-		
-		sanitizedPatient := hs.sanitizePatientForSearch(&patient)
+
+		sanitizedPatient := hs.sanitizePatientForSearch(&patient.Patient)
+		if fuzzy {
+			sanitizedPatient["match_score"] = patient.MatchScore
+		}
 		sanitizedPatients = append(sanitizedPatients, sanitizedPatient)
 	}
-	
+
 	// Log audit event for patient search
 	auditEvent := map[string]interface{}{
 		"action":      "patients_searched",
@@ -497,11 +653,12 @@ func (hs *HealthcareService) SearchPatients(c *gin.Context) {
 			"last_name":  lastName,
 			"mrn":        medicalRecordNumber,
 		},
+		"fuzzy":         fuzzy,
 		"results_count": len(sanitizedPatients),
 	}
-	
+
 	hs.auditLogger.LogEvent(auditEvent)
-	
+
 	c.JSON(http.StatusOK, gin.H{
 		"patients": sanitizedPatients,
 		"pagination": gin.H{
@@ -590,10 +747,58 @@ func (hs *HealthcareService) encryptSensitiveFields(patient *Patient) error {
 		}
 		patient.Address = encrypted
 	}
-	
+
 	return nil
 }
 
+// decryptSensitiveFields reverses encryptSensitiveFields, so callers
+// that read a patient back out of the database (e.g. SearchPatients)
+// see the plaintext phone/email/address rather than ciphertext.
+func (hs *HealthcareService) decryptSensitiveFields(patient *Patient) error {
+	if patient.PhoneNumber != "" {
+		decrypted, err := hs.decryptString(patient.PhoneNumber)
+		if err != nil {
+			return err
+		}
+		patient.PhoneNumber = decrypted
+	}
+
+	if patient.Email != "" {
+		decrypted, err := hs.decryptString(patient.Email)
+		if err != nil {
+			return err
+		}
+		patient.Email = decrypted
+	}
+
+	if patient.Address != "" {
+		decrypted, err := hs.decryptString(patient.Address)
+		if err != nil {
+			return err
+		}
+		patient.Address = decrypted
+	}
+
+	return nil
+}
+
+// sanitizePatientForSearch returns the subset of patient fields a
+// search result list should carry - enough to identify and select the
+// right patient without repeating the full record disclosure
+// GetPatientRecord performs (and its consent gating).
+func (hs *HealthcareService) sanitizePatientForSearch(patient *Patient) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                    patient.ID,
+		"medical_record_number": patient.MedicalRecordNumber,
+		"first_name":            patient.FirstName,
+		"last_name":             patient.LastName,
+		"date_of_birth":         patient.DateOfBirth.Format("2006-01-02"),
+		"gender":                patient.Gender,
+		"phone_number":          patient.PhoneNumber,
+		"email":                 patient.Email,
+	}
+}
+
 // encryptString encrypts a string using AES-256-GCM for authenticated encryption
 func (hs *HealthcareService) encryptString(plaintext string) (string, error) {
 	block, err := aes.NewCipher(hs.encryptionKey)
@@ -615,6 +820,41 @@ func (hs *HealthcareService) encryptString(plaintext string) (string, error) {
 	return fmt.Sprintf("%x", ciphertext), nil
 }
 
+// decryptString reverses encryptString. An empty input decrypts to an
+// empty string rather than erroring, since most encrypted fields are
+// optional and stored empty when never set.
+func (hs *HealthcareService) decryptString(ciphertextHex string) (string, error) {
+	if ciphertextHex == "" {
+		return "", nil
+	}
+
+	ciphertext, err := hex.DecodeString(ciphertextHex)
+	if err != nil {
+		return "", fmt.Errorf("decode ciphertext: %w", err)
+	}
+
+	block, err := aes.NewCipher(hs.encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext shorter than nonce size")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
 // validateVitalSignsRanges checks vital signs against normal ranges and
 // generates alerts for abnormal values based on patient demographics.
 func (hs *HealthcareService) validateVitalSignsRanges(vs *VitalSigns, patient *Patient) []string {
@@ -676,52 +916,106 @@ func (al *AuditLogger) LogEvent(event map[string]interface{}) error {
 }
 
 func main() {
-	// Database connection setup
-	dsn := "host=localhost user=healthcare password=secure_password dbname=healthcare_db port=5432 sslmode=require"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	// Database connection setup: one primary plus read replicas, wired
+	// together by NewHealthcareService via GORM's dbresolver plugin.
+	dbConfig := DBConfig{
+		PrimaryDSN: "host=localhost user=healthcare password=secure_password dbname=healthcare_db port=5432 sslmode=require",
+		ReplicaDSNs: []string{
+			"host=localhost user=healthcare password=secure_password dbname=healthcare_db port=5433 sslmode=require",
+			"host=localhost user=healthcare password=secure_password dbname=healthcare_db port=5434 sslmode=require",
+		},
 	}
-	
-	// Auto-migrate database schema
-	db.AutoMigrate(&Patient{}, &Allergy{}, &VitalSigns{})
-	
+
 	// Initialize encryption key (should be loaded from secure storage)
 	encryptionKey := make([]byte, 32)
 	rand.Read(encryptionKey)
-	
+
+	// Provision (or load) the internal CA and this service's server
+	// certificate for mutual TLS with service-to-service peers.
+	certManager, err := tlsauth.NewManager("certs")
+	if err != nil {
+		log.Fatal("Failed to provision TLS certificates:", err)
+	}
+	go rotateCertsPeriodically(certManager)
+
 	// Create healthcare service
-	healthcareService := NewHealthcareService(db, encryptionKey)
-	
+	healthcareService, err := NewHealthcareService(dbConfig, encryptionKey, certManager)
+	if err != nil {
+		log.Fatal("Failed to initialize healthcare service:", err)
+	}
+
+	// Auto-migrate database schema on the primary
+	healthcareService.db.AutoMigrate(&Patient{}, &Allergy{}, &VitalSigns{}, &Medication{}, &Appointment{}, &LabResult{}, &ConsentTemplate{}, &Consent{}, &ExportJob{})
+	if err := applyFuzzySearchMigration(healthcareService.db); err != nil {
+		log.Fatal("Failed to apply fuzzy search migration:", err)
+	}
+
 	// Setup Gin router
 	router := gin.Default()
-	
+
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type,Authorization")
-		
+
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
 		}
-		
+
 		c.Next()
 	})
-	
+
+	router.GET("/healthz", healthcareService.HandleHealthz)
+
 	// API routes
 	api := router.Group("/api/v1")
+	api.Use(healthcareService.forcePrimaryForRecentWrites())
 	{
 		api.POST("/patients", healthcareService.RegisterPatient)
 		api.GET("/patients/:id", healthcareService.GetPatientRecord)
 		api.GET("/patients", healthcareService.SearchPatients)
 		api.POST("/patients/:id/vital-signs", healthcareService.RecordVitalSigns)
+		api.GET("/patients/:id/observations", healthcareService.GetPatientObservations)
+		api.POST("/peers", healthcareService.EnrollPeer)
+		api.POST("/consent-templates", healthcareService.CreateConsentTemplate)
+		api.POST("/patients/:id/consents", healthcareService.SignConsent)
+		api.GET("/patients/:id/consents", healthcareService.GetPatientConsents)
+		api.DELETE("/patients/:id/consents/:consentId", healthcareService.RevokeConsent)
+		api.POST("/patients/$export", healthcareService.HandleBulkExport)
+		api.GET("/export-status/:jobId", healthcareService.HandleExportStatus)
+		api.GET("/export-download/:jobId/:filename", healthcareService.HandleExportDownload)
+
+		// Peer-only endpoints: restricted to callers presenting a valid
+		// client certificate signed by the internal CA.
+		peers := api.Group("")
+		peers.Use(tlsauth.RequireClientCert())
+		{
+			peers.POST("/fhir-callback", healthcareService.HandleFHIRCallback)
+			peers.POST("/devices/vitals", healthcareService.HandleDeviceVitals)
+			peers.POST("/lab-results", healthcareService.HandleLabResultCallback)
+		}
 	}
-	
-	// Start server
-	log.Println("Healthcare Management System starting on port 8080")
-	if err := router.Run(":8080"); err != nil {
+
+	// Start server over mTLS: every connection must present a client cert
+	// signed by the internal CA (see ServerTLSConfig), and the peer-only
+	// endpoint group above reads the caller's service_id from it.
+	log.Println("Healthcare Management System starting on port 8443 (mTLS)")
+	server := &http.Server{Addr: ":8443", Handler: router, TLSConfig: certManager.ServerTLSConfig()}
+	if err := server.ListenAndServeTLS("", ""); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// rotateCertsPeriodically checks daily whether the server certificate is
+// within 30 days of expiry and, if so, regenerates it.
+func rotateCertsPeriodically(certManager *tlsauth.Manager) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := certManager.RotateIfNeeded(); err != nil {
+			log.Printf("Error rotating TLS certificates: %v", err)
+		}
+	}
+}
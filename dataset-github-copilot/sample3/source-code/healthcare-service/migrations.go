@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// applyFuzzySearchMigration installs the Postgres extensions and
+// indexes SearchPatients' fuzzy=true path depends on. AutoMigrate
+// handles plain column/table DDL elsewhere in main(), but extensions
+// and GIN trigram indexes aren't something GORM's AutoMigrate knows how
+// to express, so they're applied here as a small one-off migration
+// instead.
+func applyFuzzySearchMigration(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE EXTENSION IF NOT EXISTS fuzzystrmatch`,
+		`CREATE INDEX IF NOT EXISTS idx_patients_first_name_trgm ON patients USING gin (first_name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_patients_last_name_trgm ON patients USING gin (last_name gin_trgm_ops)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("apply fuzzy search migration (%q): %w", statement, err)
+		}
+	}
+	return nil
+}
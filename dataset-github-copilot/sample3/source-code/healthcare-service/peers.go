@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// enrollPeerRequest is the body of POST /api/v1/peers: the CommonName to
+// issue a short-lived client certificate for (e.g. "fhir-callback",
+// "device-gateway-icu-3").
+type enrollPeerRequest struct {
+	ServiceID string `json:"service_id" binding:"required"`
+}
+
+// EnrollPeer handles POST /api/v1/peers, an admin-only endpoint that
+// issues a short-lived client certificate signed by the internal CA so
+// an external integrator (FHIR server, device gateway, lab feed) can
+// authenticate to the mTLS-protected endpoints without a shared secret.
+func (hs *HealthcareService) EnrollPeer(c *gin.Context) {
+	var req enrollPeerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	certPEM, keyPEM, err := hs.certManager.IssueClientCert(req.ServiceID)
+	if err != nil {
+		log.Printf("Error issuing peer certificate: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue peer certificate"})
+		return
+	}
+
+	auditEvent := map[string]interface{}{
+		"action":     "peer_cert_issued",
+		"service_id": req.ServiceID,
+		"user_id":    c.GetString("user_id"),
+		"timestamp":  time.Now(),
+		"ip_address": c.ClientIP(),
+	}
+	hs.auditLogger.LogEvent(auditEvent)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"service_id":  req.ServiceID,
+		"certificate": string(certPEM),
+		"private_key": string(keyPEM),
+	})
+}
+
+// HandleFHIRCallback receives asynchronous notifications from the FHIR
+// server (e.g. resource update subscriptions). Restricted to callers
+// presenting a valid peer certificate via tlsauth.RequireClientCert.
+func (hs *HealthcareService) HandleFHIRCallback(c *gin.Context) {
+	serviceID := c.GetString("service_id")
+	log.Printf("FHIR callback received from peer %q", serviceID)
+	c.JSON(http.StatusOK, gin.H{"status": "received"})
+}
+
+// HandleDeviceVitals receives vital sign readings pushed by bedside
+// monitoring devices. Restricted to callers presenting a valid peer
+// certificate via tlsauth.RequireClientCert.
+func (hs *HealthcareService) HandleDeviceVitals(c *gin.Context) {
+	serviceID := c.GetString("service_id")
+	log.Printf("Device vitals received from peer %q", serviceID)
+	c.JSON(http.StatusOK, gin.H{"status": "received"})
+}
+
+// HandleLabResultCallback receives lab result notifications pushed by an
+// external lab information system. Restricted to callers presenting a
+// valid peer certificate via tlsauth.RequireClientCert.
+func (hs *HealthcareService) HandleLabResultCallback(c *gin.Context) {
+	serviceID := c.GetString("service_id")
+	log.Printf("Lab result callback received from peer %q", serviceID)
+	c.JSON(http.StatusOK, gin.H{"status": "received"})
+}
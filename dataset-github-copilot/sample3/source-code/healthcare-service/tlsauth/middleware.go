@@ -0,0 +1,34 @@
+package tlsauth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireClientCert is gin middleware for endpoints that must only be
+// reachable by a service presenting a valid peer certificate (FHIR
+// callbacks, device gateways, lab-result senders). It extracts the
+// verified leaf certificate's CommonName and stores it on the context as
+// "service_id" for downstream handlers to authorize against.
+func RequireClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "client certificate required"})
+			return
+		}
+
+		leaf := c.Request.TLS.PeerCertificates[0]
+		serviceID := leaf.Subject.CommonName
+		if serviceID == "" && len(leaf.DNSNames) > 0 {
+			serviceID = leaf.DNSNames[0]
+		}
+		if serviceID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "client certificate has no identifying CommonName or SAN"})
+			return
+		}
+
+		c.Set("service_id", serviceID)
+		c.Next()
+	}
+}
@@ -0,0 +1,268 @@
+// Package tlsauth provisions and rotates the certificates the healthcare
+// service uses for mutual TLS between it and its service-to-service
+// peers (the FHIR callback receiver, device gateways, lab-result
+// senders), and issues short-lived client certs to integrators that
+// enroll via the peer-enrollment API.
+package tlsauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	caCommonName        = "Healthcare Service Internal CA"
+	serverCertLifetime   = 365 * 24 * time.Hour
+	clientCertLifetime   = 24 * time.Hour
+	rotateWithinOfExpiry = 30 * 24 * time.Hour
+	rsaKeyBits           = 2048
+)
+
+// Manager owns the internal CA and the server certificate derived from
+// it, and issues/rotates certificates on disk under its certDir.
+//
+//	certs/ca.crt, certs/ca.key       - internal CA, never sent to clients
+//	certs/server.crt, certs/server.key - this service's TLS server cert
+type Manager struct {
+	certDir string
+
+	caCert *x509.Certificate
+	caKey  *rsa.PrivateKey
+
+	serverCert tls.Certificate
+}
+
+// NewManager loads the CA and server certificate from certDir,
+// generating a self-signed CA and a server cert signed by it if either
+// is missing, following the same loadCert/GenCert-on-first-run pattern
+// used by the rest of the internal tooling.
+func NewManager(certDir string) (*Manager, error) {
+	if err := os.MkdirAll(certDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cert directory: %w", err)
+	}
+
+	m := &Manager{certDir: certDir}
+
+	caCert, caKey, err := m.loadOrGenerateCA()
+	if err != nil {
+		return nil, fmt.Errorf("load or generate CA: %w", err)
+	}
+	m.caCert, m.caKey = caCert, caKey
+
+	if err := m.loadOrGenerateServerCert(); err != nil {
+		return nil, fmt.Errorf("load or generate server cert: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Manager) caCertPath() string    { return filepath.Join(m.certDir, "ca.crt") }
+func (m *Manager) caKeyPath() string     { return filepath.Join(m.certDir, "ca.key") }
+func (m *Manager) serverCertPath() string { return filepath.Join(m.certDir, "server.crt") }
+func (m *Manager) serverKeyPath() string  { return filepath.Join(m.certDir, "server.key") }
+
+func (m *Manager) loadOrGenerateCA() (*x509.Certificate, *rsa.PrivateKey, error) {
+	certPEM, certErr := os.ReadFile(m.caCertPath())
+	keyPEM, keyErr := os.ReadFile(m.caKeyPath())
+	if certErr == nil && keyErr == nil {
+		cert, key, err := parseCertAndKey(certPEM, keyPEM)
+		if err == nil && time.Until(cert.NotAfter) > rotateWithinOfExpiry {
+			return cert, key, nil
+		}
+	}
+
+	return m.generateCA()
+}
+
+func (m *Manager) generateCA() (*x509.Certificate, *rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate CA key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: caCommonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse generated CA certificate: %w", err)
+	}
+
+	if err := writePEM(m.caCertPath(), "CERTIFICATE", der, 0o644); err != nil {
+		return nil, nil, err
+	}
+	if err := writePEM(m.caKeyPath(), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), 0o600); err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+func (m *Manager) loadOrGenerateServerCert() error {
+	certPEM, certErr := os.ReadFile(m.serverCertPath())
+	keyPEM, keyErr := os.ReadFile(m.serverKeyPath())
+	if certErr == nil && keyErr == nil {
+		cert, _, err := parseCertAndKey(certPEM, keyPEM)
+		if err == nil && time.Until(cert.NotAfter) > rotateWithinOfExpiry {
+			tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err == nil {
+				m.serverCert = tlsCert
+				return nil
+			}
+		}
+	}
+
+	return m.generateServerCert()
+}
+
+func (m *Manager) generateServerCert() error {
+	certPEM, keyPEM, err := m.issueCert("localhost", []string{"localhost"}, serverCertLifetime, false)
+	if err != nil {
+		return fmt.Errorf("issue server cert: %w", err)
+	}
+
+	if err := os.WriteFile(m.serverCertPath(), certPEM, 0o644); err != nil {
+		return fmt.Errorf("write server cert: %w", err)
+	}
+	if err := os.WriteFile(m.serverKeyPath(), keyPEM, 0o600); err != nil {
+		return fmt.Errorf("write server key: %w", err)
+	}
+
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("load generated server cert: %w", err)
+	}
+	m.serverCert = tlsCert
+	return nil
+}
+
+// RotateIfNeeded regenerates the server certificate if it's within 30
+// days of expiry. Call periodically (e.g. from a daily ticker) to keep
+// long-running processes' certs fresh without a restart.
+func (m *Manager) RotateIfNeeded() error {
+	cert, err := x509.ParseCertificate(m.serverCert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("parse current server cert: %w", err)
+	}
+	if time.Until(cert.NotAfter) > rotateWithinOfExpiry {
+		return nil
+	}
+	return m.generateServerCert()
+}
+
+// IssueClientCert signs a short-lived client certificate for commonName
+// (typically a service identifier like "fhir-callback" or a device
+// gateway ID), returning the PEM-encoded certificate and private key so
+// the caller can hand both to the enrolling integrator.
+func (m *Manager) IssueClientCert(commonName string) (certPEM, keyPEM []byte, err error) {
+	return m.issueCert(commonName, nil, clientCertLifetime, true)
+}
+
+func (m *Manager) issueCert(commonName string, dnsNames []string, lifetime time.Duration, clientAuth bool) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	extKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	if clientAuth {
+		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  extKeyUsage,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, m.caCert, &key.PublicKey, m.caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
+
+// ClientCAPool returns a pool containing only the internal CA, for use
+// as tls.Config.ClientCAs when requiring peer certs.
+func (m *Manager) ClientCAPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(m.caCert)
+	return pool
+}
+
+// ServerTLSConfig returns the tls.Config RunTLS-equivalents should use
+// to require and verify a peer certificate signed by the internal CA.
+func (m *Manager) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{m.serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    m.ClientCAPool(),
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// ServerCertPath and ServerKeyPath expose the on-disk server cert/key
+// paths for callers using gin's RunTLS(certFile, keyFile) directly
+// instead of a custom *http.Server with ServerTLSConfig.
+func (m *Manager) ServerCertPath() string { return m.serverCertPath() }
+func (m *Manager) ServerKeyPath() string  { return m.serverKeyPath() }
+
+func parseCertAndKey(certPEM, keyPEM []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM block found in certificate file")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM block found in key file")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	return cert, key, nil
+}
+
+func writePEM(path, blockType string, der []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return pem.Encode(f, &pem.Block{Type: blockType, Bytes: der})
+}
@@ -0,0 +1,139 @@
+package tlsauth
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+func TestNewManager_GeneratesCertsOnFirstRun(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	for _, path := range []string{m.caCertPath(), m.caKeyPath(), m.serverCertPath(), m.serverKeyPath()} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to exist: %v", path, err)
+		}
+	}
+}
+
+func TestNewManager_ReusesExistingCerts(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("first NewManager: %v", err)
+	}
+
+	second, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("second NewManager: %v", err)
+	}
+
+	if first.caCert.SerialNumber.Cmp(second.caCert.SerialNumber) != 0 {
+		t.Errorf("expected CA to be reused across runs, got different serial numbers")
+	}
+}
+
+func TestRequireClientCert_AllowsValidPeerCert(t *testing.T) {
+	m := newTestManager(t)
+
+	certPEM, keyPEM, err := m.IssueClientCert("fhir-callback")
+	if err != nil {
+		t.Fatalf("IssueClientCert: %v", err)
+	}
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequireClientCert())
+	router.GET("/peer-only", func(c *gin.Context) {
+		serviceID, _ := c.Get("service_id")
+		c.String(http.StatusOK, "%v", serviceID)
+	})
+
+	server := httptest.NewUnstartedServer(router)
+	server.TLS = m.ServerTLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates: []tls.Certificate{clientCert},
+				RootCAs:      m.ClientCAPool(),
+				// The server cert is issued for "localhost"; server.URL
+				// dials 127.0.0.1.
+				ServerName: "localhost",
+			},
+		},
+	}
+
+	resp, err := client.Get(server.URL + "/peer-only")
+	if err != nil {
+		t.Fatalf("GET /peer-only: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "fhir-callback" {
+		t.Errorf("service_id = %q, want %q", string(body), "fhir-callback")
+	}
+}
+
+func TestRequireClientCert_RejectsMissingPeerCert(t *testing.T) {
+	m := newTestManager(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequireClientCert())
+	router.GET("/peer-only", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	server := httptest.NewUnstartedServer(router)
+	serverTLS := m.ServerTLSConfig()
+	serverTLS.ClientAuth = tls.VerifyClientCertIfGiven
+	server.TLS = serverTLS
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: m.ClientCAPool(), ServerName: "localhost"},
+		},
+	}
+
+	resp, err := client.Get(server.URL + "/peer-only")
+	if err != nil {
+		t.Fatalf("GET /peer-only: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a client cert, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,143 @@
+// Package card validates and tokenizes the raw card data submitted to
+// the payment service, so that a PAN is checked and converted into a
+// Token before it ever reaches a log line, a connector, or any struct
+// that might outlive the request.
+package card
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Token is everything the rest of the payment service is allowed to
+// keep about a submitted card: a one-way identifier for it plus the
+// display details a receipt needs. The PAN itself is discarded once
+// Tokenize returns.
+type Token struct {
+	// Value identifies the PAN across requests (e.g. for refund
+	// lookups) without being reversible to it.
+	Value string
+	Last4 string
+	Brand string
+}
+
+// Tokenize hashes pan into a Token, keyed off the raw digits so the
+// same card always maps to the same Value. The PAN itself must not be
+// retained by the caller after this returns.
+func Tokenize(pan string) Token {
+	digits := strings.Map(dropNonDigits, pan)
+	sum := sha256.Sum256([]byte(digits))
+	return Token{
+		Value: "tok_" + hex.EncodeToString(sum[:])[:24],
+		Last4: last4(digits),
+		Brand: Brand(digits),
+	}
+}
+
+func last4(digits string) string {
+	if len(digits) < 4 {
+		return digits
+	}
+	return digits[len(digits)-4:]
+}
+
+func dropNonDigits(r rune) rune {
+	if r < '0' || r > '9' {
+		return -1
+	}
+	return r
+}
+
+// Brand identifies the card network from its IIN/BIN prefix. It
+// returns "unknown" for prefixes none of the networks below claim.
+func Brand(pan string) string {
+	switch {
+	case strings.HasPrefix(pan, "4"):
+		return "visa"
+	case hasPrefixInRange(pan, 51, 55), hasPrefixInRange(pan, 2221, 2720):
+		return "mastercard"
+	case strings.HasPrefix(pan, "34"), strings.HasPrefix(pan, "37"):
+		return "amex"
+	case strings.HasPrefix(pan, "6011"), strings.HasPrefix(pan, "65"):
+		return "discover"
+	default:
+		return "unknown"
+	}
+}
+
+// hasPrefixInRange reports whether pan's leading digits, parsed as a
+// number with as many digits as the bounds of [low, high], fall in
+// that range, covering Mastercard's two-digit (51-55) and six-digit
+// (222100-272099, truncated to 2221-2720 here) BIN ranges.
+func hasPrefixInRange(pan string, low, high int) bool {
+	width := len(itoa(low))
+	if len(pan) < width {
+		return false
+	}
+	prefix := atoi(pan[:width])
+	return prefix >= low && prefix <= high
+}
+
+// itoa and atoi avoid pulling in strconv for two tiny, panic-free
+// conversions over strings we already know are digit prefixes.
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	var digits []byte
+	for n > 0 {
+		digits = append([]byte{byte('0' + n%10)}, digits...)
+		n /= 10
+	}
+	return string(digits)
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+// LuhnValid reports whether pan passes the Luhn (mod 10) checksum used
+// by every major card network to catch typos and transposed digits.
+func LuhnValid(pan string) bool {
+	digits := strings.Map(dropNonDigits, pan)
+	if len(digits) < 12 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ExpiryValid reports whether month/year (year being 4-digit) is still
+// current or in the future, relative to now. Cards expire at the end
+// of their printed month, so a card expiring this month is still
+// valid.
+func ExpiryValid(month, year int, now time.Time) bool {
+	if month < 1 || month > 12 {
+		return false
+	}
+	expiry := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return current.Before(expiry)
+}
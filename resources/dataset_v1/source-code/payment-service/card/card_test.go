@@ -0,0 +1,92 @@
+package card
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		name string
+		pan  string
+		want bool
+	}{
+		{name: "valid visa test number", pan: "4242424242424242", want: true},
+		{name: "valid mastercard test number", pan: "5555555555554444", want: true},
+		{name: "last digit altered", pan: "4242424242424241", want: false},
+		{name: "too short", pan: "42424", want: false},
+		{name: "formatted with spaces", pan: "4242 4242 4242 4242", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LuhnValid(tt.pan); got != tt.want {
+				t.Errorf("LuhnValid(%q) = %v, want %v", tt.pan, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrand(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want string
+	}{
+		{pan: "4242424242424242", want: "visa"},
+		{pan: "5555555555554444", want: "mastercard"},
+		{pan: "2223003122003222", want: "mastercard"},
+		{pan: "378282246310005", want: "amex"},
+		{pan: "6011111111111117", want: "discover"},
+		{pan: "9999999999999999", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Brand(tt.pan); got != tt.want {
+				t.Errorf("Brand(%q) = %q, want %q", tt.pan, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tok := Tokenize("4242 4242 4242 4242")
+	if tok.Last4 != "4242" {
+		t.Errorf("Last4 = %q, want 4242", tok.Last4)
+	}
+	if tok.Brand != "visa" {
+		t.Errorf("Brand = %q, want visa", tok.Brand)
+	}
+	if tok.Value == "" {
+		t.Error("Value is empty")
+	}
+
+	again := Tokenize("4242424242424242")
+	if again.Value != tok.Value {
+		t.Errorf("Tokenize is not stable across formatting: %q != %q", again.Value, tok.Value)
+	}
+}
+
+func TestExpiryValid(t *testing.T) {
+	now := time.Date(2026, time.July, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		month, year int
+		want        bool
+	}{
+		{name: "expires this month", month: 7, year: 2026, want: true},
+		{name: "expires next month", month: 8, year: 2026, want: true},
+		{name: "expired last month", month: 6, year: 2026, want: false},
+		{name: "expired last year", month: 12, year: 2025, want: false},
+		{name: "invalid month", month: 13, year: 2026, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpiryValid(tt.month, tt.year, now); got != tt.want {
+				t.Errorf("ExpiryValid(%d, %d) = %v, want %v", tt.month, tt.year, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package connectors
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// LoadConfig builds the Config for the connector named name. It first
+// loads a per-connector section from the JSON file named by the
+// CONNECTOR_CONFIG_FILE environment variable, if set:
+//
+//	{"stripe": {"SECRETKEY": "sk_live_..."}, "wallet": {"APIKEY": "..."}}
+//
+// File keys are used exactly as written, so they are given in the upper
+// case the connectors read. It then overlays environment variables of the form
+// CONNECTOR_<NAME>_<KEY> (e.g. CONNECTOR_STRIPE_SECRETKEY), which take
+// precedence so a deployment can override the file without editing it.
+func LoadConfig(name string) Config {
+	config := make(Config)
+
+	if path := os.Getenv("CONNECTOR_CONFIG_FILE"); path != "" {
+		if raw, err := os.ReadFile(path); err == nil {
+			var all map[string]Config
+			if err := json.Unmarshal(raw, &all); err == nil {
+				for key, value := range all[name] {
+					config[key] = value
+				}
+			}
+		}
+	}
+
+	prefix := "CONNECTOR_" + strings.ToUpper(name) + "_"
+	for _, entry := range os.Environ() {
+		key, value, found := strings.Cut(entry, "=")
+		if !found || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		config[strings.TrimPrefix(key, prefix)] = value
+	}
+
+	return config
+}
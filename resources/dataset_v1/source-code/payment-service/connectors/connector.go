@@ -0,0 +1,65 @@
+// Package connectors defines the pluggable payment gateway abstraction
+// that the payment service's process-payment, refund, and
+// transaction-status endpoints are routed through. A request selects
+// its connector by name (PaymentRequest.Connector), and the registry in
+// registry.go resolves that name to a concrete Connector built from its
+// own Config, so adding a gateway never touches the HTTP handlers.
+package connectors
+
+import (
+	"context"
+	"time"
+)
+
+// AuthorizeRequest carries everything a connector needs to place a hold
+// for Amount against the given payment method. The PAN itself never
+// reaches this struct: the caller tokenizes it first (see package
+// card) and passes only the token plus the display details a receipt
+// needs.
+type AuthorizeRequest struct {
+	OrderID     string
+	Amount      float64
+	Currency    string
+	CardToken   string
+	CardLast4   string
+	CardBrand   string
+	ExpiryMonth int
+	ExpiryYear  int
+	CVV         string
+}
+
+// Transaction is the connector-agnostic result of Authorize, Capture,
+// Refund, and Void, and what GetTransaction returns for a transaction
+// created earlier.
+type Transaction struct {
+	ID        string
+	Status    string // "AUTHORIZED", "REQUIRES_ACTION", "CAPTURED", "REFUNDED", "VOIDED"
+	Amount    float64
+	Currency  string
+	CreatedAt time.Time
+}
+
+// Connector is implemented by each supported payment gateway.
+type Connector interface {
+	// Authorize places a hold for req.Amount against the given payment
+	// method and returns the resulting Transaction. A connector that
+	// supports step-up authentication may return a Transaction with
+	// Status "REQUIRES_ACTION" instead of failing outright; the caller
+	// must route the cardholder through an out-of-band challenge and
+	// call Confirm once it's complete.
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error)
+	// Confirm completes a transaction left in "REQUIRES_ACTION" by
+	// Authorize, moving it to "AUTHORIZED" once the caller reports the
+	// cardholder challenge succeeded.
+	Confirm(ctx context.Context, transactionID string) (*Transaction, error)
+	// Capture settles a previously authorized transaction, optionally
+	// for less than the originally authorized amount.
+	Capture(ctx context.Context, transactionID string, amount float64) (*Transaction, error)
+	// Refund returns amount to the original payment method for a
+	// captured transaction.
+	Refund(ctx context.Context, transactionID string, amount float64) (*Transaction, error)
+	// Void cancels a transaction that was authorized but never captured.
+	Void(ctx context.Context, transactionID string) (*Transaction, error)
+	// GetTransaction looks up the current state of a transaction by ID.
+	GetTransaction(ctx context.Context, transactionID string) (*Transaction, error)
+}
@@ -0,0 +1,44 @@
+package connectors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Config is a connector's configuration, loaded from the environment by
+// LoadConfig before the connector is constructed.
+type Config map[string]string
+
+// Factory builds a Connector from its Config. Connector implementations
+// register a Factory from an init function rather than being
+// constructed directly, so the registry stays the single place that
+// knows which names are available.
+type Factory func(config Config) (Connector, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register associates name with the Factory used to construct that
+// connector. Registering the same name twice is a programming error and panics,
+// matching the standard library's database/sql driver registry.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := factories[name]; exists {
+		panic("connectors: Register called twice for connector " + name)
+	}
+	factories[name] = factory
+}
+
+// New constructs the connector registered under name using config.
+func New(name string, config Config) (Connector, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("connectors: no connector registered for %q", name)
+	}
+	return factory(config)
+}
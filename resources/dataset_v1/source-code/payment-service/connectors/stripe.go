@@ -0,0 +1,136 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("stripe", newStripeConnector)
+}
+
+// defaultThreeDSThreshold is the charge amount, in the request
+// currency's minor-unit-free float, at or above which Stripe's own
+// risk engine typically asks the issuer to step up with 3-D Secure.
+// Overridable per deployment via the THREEDSTHRESHOLD config key.
+const defaultThreeDSThreshold = 500.00
+
+// stripeConnector implements Connector against a Stripe-style
+// authorize-then-capture charge API. It's a simulation (no outbound
+// network calls): good enough to exercise the process-payment, refund,
+// and transaction-status flows end-to-end without a live Stripe account.
+type stripeConnector struct {
+	secretKey        string
+	threeDSThreshold float64
+
+	mu           sync.Mutex
+	transactions map[string]*Transaction
+}
+
+func newStripeConnector(config Config) (Connector, error) {
+	secretKey := config["SECRETKEY"]
+	if secretKey == "" {
+		return nil, fmt.Errorf("connectors: stripe connector requires SECRETKEY config")
+	}
+
+	threshold := defaultThreeDSThreshold
+	if raw := config["THREEDSTHRESHOLD"]; raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("connectors: stripe connector THREEDSTHRESHOLD must be a number: %w", err)
+		}
+		threshold = parsed
+	}
+
+	return &stripeConnector{secretKey: secretKey, threeDSThreshold: threshold, transactions: make(map[string]*Transaction)}, nil
+}
+
+// Authorize places a hold for req.Amount. Charges at or above the
+// connector's 3DS threshold come back as "REQUIRES_ACTION" instead of
+// "AUTHORIZED": the caller must walk the cardholder through a
+// challenge out-of-band and call Confirm to finish authorizing it.
+func (c *stripeConnector) Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error) {
+	status := "AUTHORIZED"
+	if req.Amount >= c.threeDSThreshold {
+		status = "REQUIRES_ACTION"
+	}
+
+	txn := &Transaction{
+		ID:        "ch_" + randomHex(),
+		Status:    status,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		CreatedAt: time.Now(),
+	}
+
+	c.mu.Lock()
+	c.transactions[txn.ID] = txn
+	c.mu.Unlock()
+
+	return txn, nil
+}
+
+// Confirm completes a transaction that Authorize left in
+// "REQUIRES_ACTION" once the cardholder has cleared the 3DS challenge.
+func (c *stripeConnector) Confirm(ctx context.Context, transactionID string) (*Transaction, error) {
+	return c.transition(transactionID, "REQUIRES_ACTION", "AUTHORIZED", 0)
+}
+
+func (c *stripeConnector) Capture(ctx context.Context, transactionID string, amount float64) (*Transaction, error) {
+	return c.transition(transactionID, "AUTHORIZED", "CAPTURED", amount)
+}
+
+func (c *stripeConnector) Refund(ctx context.Context, transactionID string, amount float64) (*Transaction, error) {
+	return c.transition(transactionID, "CAPTURED", "REFUNDED", amount)
+}
+
+func (c *stripeConnector) Void(ctx context.Context, transactionID string) (*Transaction, error) {
+	return c.transition(transactionID, "AUTHORIZED", "VOIDED", 0)
+}
+
+func (c *stripeConnector) GetTransaction(ctx context.Context, transactionID string) (*Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txn, ok := c.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("connectors: stripe transaction %q not found", transactionID)
+	}
+	copied := *txn
+	return &copied, nil
+}
+
+// transition enforces that transactionID is currently in fromStatus
+// before moving it to toStatus, and records the new amount when it's
+// non-zero (a Void leaves the authorized amount untouched).
+func (c *stripeConnector) transition(transactionID, fromStatus, toStatus string, amount float64) (*Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txn, ok := c.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("connectors: stripe transaction %q not found", transactionID)
+	}
+	if txn.Status != fromStatus {
+		return nil, fmt.Errorf("connectors: stripe transaction %q is %s, not %s", transactionID, txn.Status, fromStatus)
+	}
+
+	txn.Status = toStatus
+	if amount > 0 {
+		txn.Amount = amount
+	}
+
+	copied := *txn
+	return &copied, nil
+}
+
+func randomHex() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,102 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("wallet", newWalletConnector)
+}
+
+// walletConnector implements Connector against a MangoPay/Modulr-style
+// wallet-transfer API: instead of a card charge, Authorize reserves
+// funds as a pending transfer out of the merchant's source wallet, and
+// Capture confirms it into the settled balance. Like stripeConnector,
+// it's a simulation with no outbound network calls.
+type walletConnector struct {
+	apiKey       string
+	sourceWallet string
+
+	mu           sync.Mutex
+	transactions map[string]*Transaction
+}
+
+func newWalletConnector(config Config) (Connector, error) {
+	apiKey := config["APIKEY"]
+	sourceWallet := config["SOURCEWALLET"]
+	if apiKey == "" || sourceWallet == "" {
+		return nil, fmt.Errorf("connectors: wallet connector requires APIKEY and SOURCEWALLET config")
+	}
+	return &walletConnector{apiKey: apiKey, sourceWallet: sourceWallet, transactions: make(map[string]*Transaction)}, nil
+}
+
+func (c *walletConnector) Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error) {
+	txn := &Transaction{
+		ID:        "wt_" + randomHex(),
+		Status:    "AUTHORIZED",
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		CreatedAt: time.Now(),
+	}
+
+	c.mu.Lock()
+	c.transactions[txn.ID] = txn
+	c.mu.Unlock()
+
+	return txn, nil
+}
+
+// Confirm always fails: wallet-to-wallet transfers have no cardholder
+// step-up equivalent, so Authorize never leaves a transaction in
+// "REQUIRES_ACTION" for it to complete.
+func (c *walletConnector) Confirm(ctx context.Context, transactionID string) (*Transaction, error) {
+	return nil, fmt.Errorf("connectors: wallet transfer %q does not support Confirm", transactionID)
+}
+
+func (c *walletConnector) Capture(ctx context.Context, transactionID string, amount float64) (*Transaction, error) {
+	return c.transition(transactionID, "AUTHORIZED", "CAPTURED", amount)
+}
+
+func (c *walletConnector) Refund(ctx context.Context, transactionID string, amount float64) (*Transaction, error) {
+	return c.transition(transactionID, "CAPTURED", "REFUNDED", amount)
+}
+
+func (c *walletConnector) Void(ctx context.Context, transactionID string) (*Transaction, error) {
+	return c.transition(transactionID, "AUTHORIZED", "VOIDED", 0)
+}
+
+func (c *walletConnector) GetTransaction(ctx context.Context, transactionID string) (*Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txn, ok := c.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("connectors: wallet transfer %q not found", transactionID)
+	}
+	copied := *txn
+	return &copied, nil
+}
+
+func (c *walletConnector) transition(transactionID, fromStatus, toStatus string, amount float64) (*Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txn, ok := c.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("connectors: wallet transfer %q not found", transactionID)
+	}
+	if txn.Status != fromStatus {
+		return nil, fmt.Errorf("connectors: wallet transfer %q is %s, not %s", transactionID, txn.Status, fromStatus)
+	}
+
+	txn.Status = toStatus
+	if amount > 0 {
+		txn.Amount = amount
+	}
+
+	copied := *txn
+	return &copied, nil
+}
@@ -0,0 +1,110 @@
+// Package idempotency implements request deduplication for the payment
+// service's mutating endpoints via the Idempotency-Key header: the
+// first request with a given key runs the handler and caches its
+// response in Redis; a retry with the same key (e.g. after a client
+// timeout) replays the cached response instead of re-running the
+// handler, so a resubmitted payment can't be charged twice.
+package idempotency
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	headerName = "Idempotency-Key"
+	// responseTTL is how long a completed response stays cached and
+	// replayable for a retried request with the same key.
+	responseTTL = 24 * time.Hour
+	// lockTTL bounds how long a request can hold the in-progress lock,
+	// so a crash mid-request doesn't wedge the key forever.
+	lockTTL = 30 * time.Second
+)
+
+// cachedResponse is the JSON shape stored in Redis for a completed
+// request, so a replay can reproduce the exact status and body the
+// first execution produced.
+type cachedResponse struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// Wrap returns an http.HandlerFunc that deduplicates requests to route
+// (used only to namespace the Redis keys between routes) by their
+// Idempotency-Key header, delegating to next. Requests without the
+// header pass through unchanged.
+func Wrap(client *redis.Client, route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(headerName)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		responseKey := "idem:" + route + ":" + key
+		lockKey := responseKey + ":lock"
+
+		if cached, err := client.Get(ctx, responseKey).Bytes(); err == nil {
+			replay(w, cached)
+			return
+		}
+
+		acquired, err := client.SetNX(ctx, lockKey, "1", lockTTL).Result()
+		if err != nil {
+			// Redis is unavailable; fail open rather than blocking payment.
+			next(w, r)
+			return
+		}
+		if !acquired {
+			http.Error(w, "a request with this Idempotency-Key is already in progress", http.StatusConflict)
+			return
+		}
+		defer client.Del(ctx, lockKey)
+
+		recorder := &responseRecorder{ResponseWriter: w, body: &bytes.Buffer{}, status: http.StatusOK}
+		next(recorder, r)
+
+		if recorder.status < http.StatusInternalServerError {
+			encoded, err := json.Marshal(cachedResponse{Status: recorder.status, Body: recorder.body.Bytes()})
+			if err == nil {
+				client.Set(ctx, responseKey, encoded, responseTTL)
+			}
+		}
+	}
+}
+
+// replay writes a previously cached response in place of executing the
+// handler again.
+func replay(w http.ResponseWriter, cached []byte) {
+	var response cachedResponse
+	if err := json.Unmarshal(cached, &response); err != nil {
+		http.Error(w, "failed to replay cached response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Status)
+	w.Write(response.Body)
+}
+
+// responseRecorder captures the status code and body the wrapped
+// handler writes, so Wrap can cache exactly what the client received.
+type responseRecorder struct {
+	http.ResponseWriter
+	body   *bytes.Buffer
+	status int
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
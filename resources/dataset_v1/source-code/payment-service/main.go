@@ -1,13 +1,38 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"payment-service/card"
+	"payment-service/connectors"
+	"payment-service/idempotency"
+	"payment-service/observability"
 )
 
+// logger is the trace-aware structured logger used across request
+// handlers, initialized in main once tracing is up.
+var logger *slog.Logger
+
+// defaultConnector is used when a PaymentRequest doesn't name one
+// explicitly, preserving the pre-connectors behavior of always going
+// through Stripe.
+const defaultConnector = "stripe"
+
+// redisClient backs the Idempotency-Key deduplication on
+// process-payment. It's initialized in main.
+var redisClient *redis.Client
+
 // PaymentRequest defines the structure for an incoming payment request.
 // All fields use camelCase as per the organization's coding standards.
 type PaymentRequest struct {
@@ -17,6 +42,9 @@ type PaymentRequest struct {
 	ExpiryMonth int     `json:"expiryMonth"`
 	ExpiryYear  int     `json:"expiryYear"`
 	CVV         string  `json:"cvv"`
+	// Connector selects which registered connectors.Connector processes
+	// this request (e.g. "stripe", "wallet"). Defaults to defaultConnector.
+	Connector string `json:"connector"`
 }
 
 // PaymentResponse defines the structure for a payment response.
@@ -24,17 +52,114 @@ type PaymentResponse struct {
 	TransactionID string `json:"transactionId"`
 	Status        string `json:"status"`
 	Message       string `json:"message"`
+	// ChallengeURL is set only when Status is "REQUIRES_ACTION": the
+	// client must complete the 3DS challenge it points to, then POST to
+	// /api/v1/process-payment/{transactionId}/confirm to finish.
+	ChallengeURL string `json:"challengeUrl,omitempty"`
 }
 
-// A simple logging function that adheres to the org-wide format.
-func logRequest(level, message string) {
-	// Compliant with [LEVEL] - {YYYY-MM-DD HH:mm:ss} - Message
-	timestamp := time.Now().UTC().Format("2006-01-02 15:04:05")
-	log.Printf("[%s] - %s - %s", level, timestamp, message)
+// RefundRequest defines the structure for an incoming refund request.
+type RefundRequest struct {
+	TransactionID string  `json:"transactionId"`
+	Amount        float64 `json:"amount"`
+}
+
+// transactionConnectors remembers which connector authorized each
+// transaction ID, so a later refund/void/status call can be routed back
+// to the same connector without the caller having to repeat it.
+var (
+	transactionConnectorsMu sync.Mutex
+	transactionConnectors   = make(map[string]string)
+)
+
+// connectorInstances caches the single Connector built for each name,
+// since connectors hold in-memory transaction state that a fresh
+// instance per request would lose.
+var (
+	connectorInstancesMu sync.Mutex
+	connectorInstances   = make(map[string]connectors.Connector)
+)
+
+// pendingPaymentTTL bounds how long a transaction can sit in
+// "REQUIRES_ACTION" waiting for the cardholder to clear a 3DS
+// challenge before /confirm stops recognizing it.
+const pendingPaymentTTL = 15 * time.Minute
+
+// pendingPayment is what's persisted in Redis for a transaction
+// Authorize left in "REQUIRES_ACTION", so /confirm has enough to
+// finish it without the client having to resend the order or amount.
+type pendingPayment struct {
+	OrderID   string  `json:"orderId"`
+	Amount    float64 `json:"amount"`
+	Connector string  `json:"connector"`
+}
+
+func pendingPaymentKey(transactionID string) string {
+	return "payment:pending:" + transactionID
+}
+
+// savePendingPayment persists pending under transactionID for later
+// retrieval by confirmPaymentHandler.
+func savePendingPayment(ctx context.Context, transactionID string, pending pendingPayment) error {
+	encoded, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return redisClient.Set(ctx, pendingPaymentKey(transactionID), encoded, pendingPaymentTTL).Err()
+}
+
+// loadPendingPayment retrieves the pendingPayment saved for
+// transactionID, returning an error if it was never stored or has
+// expired.
+func loadPendingPayment(ctx context.Context, transactionID string) (pendingPayment, error) {
+	var pending pendingPayment
+	raw, err := redisClient.Get(ctx, pendingPaymentKey(transactionID)).Bytes()
+	if err != nil {
+		return pending, fmt.Errorf("payment %q is not pending confirmation: %w", transactionID, err)
+	}
+	if err := json.Unmarshal(raw, &pending); err != nil {
+		return pending, err
+	}
+	return pending, nil
+}
+
+// redisAddrFromEnv reads the Redis address backing the idempotency
+// store from the environment, defaulting to the standard local port.
+func redisAddrFromEnv() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
+
+// connectorFor resolves, constructing and caching it on first use, the
+// connector named by name, falling back to defaultConnector when name
+// is empty.
+func connectorFor(name string) (connectors.Connector, string, error) {
+	if name == "" {
+		name = defaultConnector
+	}
+
+	connectorInstancesMu.Lock()
+	defer connectorInstancesMu.Unlock()
+
+	if conn, ok := connectorInstances[name]; ok {
+		return conn, name, nil
+	}
+
+	conn, err := connectors.New(name, connectors.LoadConfig(name))
+	if err != nil {
+		return nil, name, err
+	}
+	connectorInstances[name] = conn
+	return conn, name, nil
 }
 
 // processPaymentHandler handles the /api/v1/process-payment endpoint.
-// It validates the request and simulates a payment transaction.
+// It validates the request, then authorizes and immediately captures
+// the payment through the connector named by req.Connector. When the
+// connector asks for a 3DS challenge instead, it stores the pending
+// payment and responds 202 with the challenge URL without capturing.
 func processPaymentHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -45,33 +170,87 @@ func processPaymentHandler(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	err := decoder.Decode(&req)
 	if err != nil {
-		logRequest("ERROR", "Failed to decode payment request body")
+		logger.ErrorContext(r.Context(), "Failed to decode payment request body")
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	logRequest("INFO", "Processing payment for OrderID: "+req.OrderID)
+	ctx := observability.WithOrderID(r.Context(), req.OrderID)
+	logger.InfoContext(ctx, "Processing payment")
 
 	// Basic validation, as per security policies.
 	if !validatePaymentRequest(req) {
-		logRequest("WARN", "Invalid payment data for OrderID: "+req.OrderID)
+		logger.WarnContext(ctx, "Invalid payment data")
 		http.Error(w, "Invalid payment data", http.StatusBadRequest)
 		return
 	}
 
-	// Simulate payment processing with an external gateway.
-	// This would involve a call to Stripe, Braintree, etc.
-	time.Sleep(1 * time.Second) // Simulate network latency.
+	conn, connectorName, err := connectorFor(req.Connector)
+	if err != nil {
+		logger.ErrorContext(ctx, "Unknown connector", "error", err)
+		http.Error(w, "Unsupported connector", http.StatusBadRequest)
+		return
+	}
+
+	// Tokenize immediately: req.CreditCard must not be read again past
+	// this point, so the PAN never reaches a connector, a log line, or
+	// any struct that outlives this request.
+	token := card.Tokenize(req.CreditCard)
+
+	txn, err := conn.Authorize(ctx, connectors.AuthorizeRequest{
+		OrderID:     req.OrderID,
+		Amount:      req.Amount,
+		Currency:    "USD",
+		CardToken:   token.Value,
+		CardLast4:   token.Last4,
+		CardBrand:   token.Brand,
+		ExpiryMonth: req.ExpiryMonth,
+		ExpiryYear:  req.ExpiryYear,
+		CVV:         req.CVV,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Authorization failed", "error", err)
+		http.Error(w, "Payment authorization failed", http.StatusBadGateway)
+		return
+	}
+
+	transactionConnectorsMu.Lock()
+	transactionConnectors[txn.ID] = connectorName
+	transactionConnectorsMu.Unlock()
+
+	if txn.Status == "REQUIRES_ACTION" {
+		pending := pendingPayment{OrderID: req.OrderID, Amount: req.Amount, Connector: connectorName}
+		if err := savePendingPayment(ctx, txn.ID, pending); err != nil {
+			logger.ErrorContext(ctx, "Failed to persist pending 3DS transaction", "transaction_id", txn.ID, "error", err)
+			http.Error(w, "Payment authorization failed", http.StatusBadGateway)
+			return
+		}
 
-	// In a real app, the transaction ID would come from the payment gateway.
-	transactionID := "txn_" + req.OrderID
-	status := "SUCCESS"
-	message := "Payment processed successfully."
+		logger.InfoContext(ctx, "Payment requires 3DS challenge", "transaction_id", txn.ID, "card_brand", token.Brand, "card_last4", token.Last4)
+		response := PaymentResponse{
+			TransactionID: txn.ID,
+			Status:        txn.Status,
+			Message:       "Cardholder authentication required.",
+			ChallengeURL:  challengeURL(txn.ID),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	txn, err = conn.Capture(ctx, txn.ID, req.Amount)
+	if err != nil {
+		logger.ErrorContext(ctx, "Capture failed", "error", err)
+		http.Error(w, "Payment capture failed", http.StatusBadGateway)
+		return
+	}
 
 	response := PaymentResponse{
-		TransactionID: transactionID,
-		Status:        status,
-		Message:       message,
+		TransactionID: txn.ID,
+		Status:        "SUCCESS",
+		Message:       "Payment processed successfully.",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -79,12 +258,87 @@ func processPaymentHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// validatePaymentRequest performs basic checks on the incoming payment data.
+// challengeURL builds the URL the cardholder completes the 3DS
+// challenge at. This simulation routes it straight back to the
+// confirm endpoint; a live integration would instead point here at
+// the connector's own hosted challenge page.
+func challengeURL(transactionID string) string {
+	return "/api/v1/process-payment/" + transactionID + "/confirm"
+}
+
+// confirmPaymentHandler handles
+// POST /api/v1/process-payment/{transactionId}/confirm, resuming a
+// transaction Authorize left in "REQUIRES_ACTION" once the cardholder
+// has cleared the 3DS challenge.
+func confirmPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/v1/process-payment/")
+	transactionID := strings.TrimSuffix(rest, "/confirm")
+	if transactionID == "" || transactionID == rest {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	logger.InfoContext(ctx, "Payment confirmation requested", "transaction_id", transactionID)
+
+	pending, err := loadPendingPayment(ctx, transactionID)
+	if err != nil {
+		logger.WarnContext(ctx, "Confirm failed: no pending transaction", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Unknown or expired transaction", http.StatusNotFound)
+		return
+	}
+
+	conn, _, err := connectorFor(pending.Connector)
+	if err != nil {
+		logger.ErrorContext(ctx, "Confirm failed: unknown connector", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Unsupported connector", http.StatusBadGateway)
+		return
+	}
+
+	txn, err := conn.Confirm(ctx, transactionID)
+	if err != nil {
+		logger.ErrorContext(ctx, "3DS confirmation failed", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Payment confirmation failed", http.StatusBadGateway)
+		return
+	}
+
+	txn, err = conn.Capture(ctx, txn.ID, pending.Amount)
+	if err != nil {
+		logger.ErrorContext(ctx, "Capture failed", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Payment capture failed", http.StatusBadGateway)
+		return
+	}
+
+	redisClient.Del(ctx, pendingPaymentKey(transactionID))
+
+	response := PaymentResponse{
+		TransactionID: txn.ID,
+		Status:        "SUCCESS",
+		Message:       "Payment confirmed and processed successfully.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// validatePaymentRequest performs basic checks on the incoming payment data,
+// including a Luhn checksum on the card number and its expiry date.
 func validatePaymentRequest(req PaymentRequest) bool {
 	if req.OrderID == "" || req.Amount <= 0 || len(req.CreditCard) != 16 || len(req.CVV) != 3 {
 		return false
 	}
-	// Add more validation for expiry date, card number format (Luhn algorithm), etc.
+	if !card.LuhnValid(req.CreditCard) {
+		return false
+	}
+	if !card.ExpiryValid(req.ExpiryMonth, req.ExpiryYear, time.Now()) {
+		return false
+	}
 	return true
 }
 
@@ -94,25 +348,138 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Payment service is UP"))
 }
 
-// refundPaymentHandler is a placeholder for a refund endpoint.
+// refundPaymentHandler handles the /api/v1/refund endpoint, routing the
+// refund to whichever connector originally authorized the transaction.
 func refundPaymentHandler(w http.ResponseWriter, r *http.Request) {
-	logRequest("INFO", "Refund requested")
-	w.WriteHeader(http.StatusNotImplemented)
-	w.Write([]byte("Refund functionality not implemented yet."))
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode refund request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	logger.InfoContext(ctx, "Refund requested", "transaction_id", req.TransactionID)
+
+	if req.TransactionID == "" || req.Amount <= 0 {
+		logger.WarnContext(ctx, "Invalid refund data", "transaction_id", req.TransactionID)
+		http.Error(w, "Invalid refund data", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := connectorForTransaction(req.TransactionID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Refund failed: unknown transaction", "transaction_id", req.TransactionID, "error", err)
+		http.Error(w, "Unknown transaction", http.StatusNotFound)
+		return
+	}
+
+	txn, err := conn.Refund(ctx, req.TransactionID, req.Amount)
+	if err != nil {
+		logger.ErrorContext(ctx, "Refund failed", "transaction_id", req.TransactionID, "error", err)
+		http.Error(w, "Refund failed", http.StatusBadGateway)
+		return
+	}
+
+	response := PaymentResponse{
+		TransactionID: txn.ID,
+		Status:        "REFUNDED",
+		Message:       "Refund processed successfully.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
 }
 
-// getTransactionStatusHandler is another placeholder for a status check endpoint.
+// getTransactionStatusHandler handles the /api/v1/transaction/status
+// endpoint, looking up the transaction named by the transactionId query
+// parameter through whichever connector authorized it.
 func getTransactionStatusHandler(w http.ResponseWriter, r *http.Request) {
-	logRequest("INFO", "Transaction status requested")
-	w.WriteHeader(http.StatusNotImplemented)
-	w.Write([]byte("Transaction status functionality not implemented yet."))
+	transactionID := r.URL.Query().Get("transactionId")
+	ctx := r.Context()
+	logger.InfoContext(ctx, "Transaction status requested", "transaction_id", transactionID)
+
+	if transactionID == "" {
+		http.Error(w, "Missing transactionId query parameter", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := connectorForTransaction(transactionID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Status lookup failed: unknown transaction", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Unknown transaction", http.StatusNotFound)
+		return
+	}
+
+	txn, err := conn.GetTransaction(ctx, transactionID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Status lookup failed", "transaction_id", transactionID, "error", err)
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	response := PaymentResponse{
+		TransactionID: txn.ID,
+		Status:        txn.Status,
+		Message:       "",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// connectorForTransaction resolves the connector that authorized
+// transactionID, recorded by processPaymentHandler at authorization
+// time.
+func connectorForTransaction(transactionID string) (connectors.Connector, error) {
+	transactionConnectorsMu.Lock()
+	name, ok := transactionConnectors[transactionID]
+	transactionConnectorsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no connector recorded for transaction %q", transactionID)
+	}
+
+	conn, _, err := connectorFor(name)
+	return conn, err
 }
 
 func main() {
+	shutdownTracer, err := observability.InitTracer(context.Background(), "payment-service")
+	if err != nil {
+		log.Fatal("Failed to initialize tracing:", err)
+	}
+	defer func() {
+		if err := shutdownTracer(context.Background()); err != nil {
+			log.Printf("Failed to shut down tracer: %v", err)
+		}
+	}()
+	logger = observability.NewLogger()
+
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: redisAddrFromEnv(),
+	})
+	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
+		logger.Warn("Redis unavailable, Idempotency-Key requests will not be deduplicated", "error", err)
+	}
+
 	// This setup is fully compliant with all known documentation.
 	// It uses the /api/v1/ prefix and exposes a /health endpoint.
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/process-payment", processPaymentHandler)
+	// Idempotency-Key-protected: a retried process-payment POST (e.g.
+	// after a client-side timeout) replays the first response instead of
+	// authorizing and capturing the charge a second time.
+	mux.HandleFunc("/api/v1/process-payment", idempotency.Wrap(redisClient, "process-payment", processPaymentHandler))
+	// Not Idempotency-Key-protected: a transaction only leaves
+	// "REQUIRES_ACTION" once, so a retried confirm is already handled
+	// by Confirm/Capture's own state checks.
+	mux.HandleFunc("/api/v1/process-payment/", confirmPaymentHandler)
 	mux.HandleFunc("/api/v1/refund", refundPaymentHandler)
 	mux.HandleFunc("/api/v1/transaction/status", getTransactionStatusHandler)
 	mux.HandleFunc("/health", healthCheckHandler)
@@ -122,8 +489,13 @@ func main() {
 		port = "8083" // Default port if not specified
 	}
 
-	logRequest("INFO", "Payment Service starting on port "+port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
+	// Wrapping the whole mux (rather than each route) extracts an
+	// inbound traceparent header, if the caller sent one, before any
+	// handler or logger.*Context call runs.
+	handler := observability.Middleware(mux, "payment-service")
+
+	logger.Info("Payment Service starting", "port", port)
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
 		log.Fatalf("Could not start server: %s\n", err)
 	}
 }
@@ -0,0 +1,59 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey string
+
+const orderIDKey contextKey = "order_id"
+
+// WithOrderID returns a context carrying orderID, so any log line
+// emitted through it attaches an order_id attribute, correlating it
+// with the order service's own logs for the same order.
+func WithOrderID(ctx context.Context, orderID string) context.Context {
+	return context.WithValue(ctx, orderIDKey, orderID)
+}
+
+// NewLogger returns a JSON slog.Logger whose handler attaches
+// trace_id/span_id from the active span and order_id (when present) on
+// the record's context, so a log line can be correlated with the span
+// and order it belongs to.
+func NewLogger() *slog.Logger {
+	return slog.New(&traceContextHandler{next: slog.NewJSONHandler(os.Stdout, nil)})
+}
+
+// traceContextHandler wraps an slog.Handler, adding trace_id/span_id/
+// order_id attributes drawn from the record's context.
+type traceContextHandler struct {
+	next slog.Handler
+}
+
+func (h *traceContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *traceContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.SpanContext().TraceID().String()),
+			slog.String("span_id", span.SpanContext().SpanID().String()),
+		)
+	}
+	if orderID, ok := ctx.Value(orderIDKey).(string); ok && orderID != "" {
+		record.AddAttrs(slog.String("order_id", orderID))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *traceContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *traceContextHandler) WithGroup(name string) slog.Handler {
+	return &traceContextHandler{next: h.next.WithGroup(name)}
+}
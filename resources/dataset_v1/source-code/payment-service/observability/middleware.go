@@ -0,0 +1,16 @@
+package observability
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Middleware wraps next with otelhttp instrumentation: it extracts an
+// inbound traceparent header (via the global propagator set up by
+// InitTracer) and starts a server span as its child, so a request that
+// arrived already carrying a trace from an upstream caller continues
+// that trace rather than starting a new one.
+func Middleware(next http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(next, operation)
+}
@@ -0,0 +1,116 @@
+// Package idempotency implements request deduplication for mutating
+// endpoints via the Idempotency-Key header: the first request with a
+// given key runs the handler and caches its response in Redis; any
+// retry with the same key (e.g. after a client timeout) replays the
+// cached response instead of re-running the handler, so a resubmitted
+// CreateOrder can't charge or insert an order twice.
+package idempotency
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/go-redis/redis/v8"
+)
+
+const (
+    headerName = "Idempotency-Key"
+    // responseTTL is how long a completed response stays cached and
+    // replayable for a retried request with the same key.
+    responseTTL = 24 * time.Hour
+    // lockTTL bounds how long a request can hold the in-progress lock,
+    // so a crash mid-request doesn't wedge the key forever.
+    lockTTL = 30 * time.Second
+)
+
+// cachedResponse is the JSON shape stored in Redis for a completed
+// request, so a replay can reproduce the exact status and body the
+// first execution produced.
+type cachedResponse struct {
+    Status int             `json:"status"`
+    Body   json.RawMessage `json:"body"`
+}
+
+// Middleware returns Gin middleware that deduplicates requests to route
+// (used only to namespace the Redis keys between routes) by their
+// Idempotency-Key header, using client for locking and caching.
+// Requests without the header pass through unchanged.
+func Middleware(client *redis.Client, route string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        key := c.GetHeader(headerName)
+        if key == "" {
+            c.Next()
+            return
+        }
+
+        ctx := c.Request.Context()
+        responseKey := "idem:" + route + ":" + key
+        lockKey := responseKey + ":lock"
+
+        if cached, err := client.Get(ctx, responseKey).Bytes(); err == nil {
+            replay(c, cached)
+            return
+        }
+
+        acquired, err := client.SetNX(ctx, lockKey, "1", lockTTL).Result()
+        if err != nil {
+            // Redis is unavailable; fail open rather than blocking checkout.
+            c.Next()
+            return
+        }
+        if !acquired {
+            c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is already in progress"})
+            return
+        }
+        defer client.Del(ctx, lockKey)
+
+        recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}, status: http.StatusOK}
+        c.Writer = recorder
+
+        c.Next()
+
+        if len(c.Errors) == 0 && recorder.status < http.StatusInternalServerError {
+            encoded, err := json.Marshal(cachedResponse{Status: recorder.status, Body: recorder.body.Bytes()})
+            if err == nil {
+                client.Set(ctx, responseKey, encoded, responseTTL)
+            }
+        }
+    }
+}
+
+// replay writes a previously cached response in place of executing the
+// handler again.
+func replay(c *gin.Context, cached []byte) {
+    var response cachedResponse
+    if err := json.Unmarshal(cached, &response); err != nil {
+        c.Next()
+        return
+    }
+    c.Data(response.Status, "application/json", response.Body)
+    c.Abort()
+}
+
+// responseRecorder captures the status code and body Gin writes, so
+// Middleware can cache exactly what the client received.
+type responseRecorder struct {
+    gin.ResponseWriter
+    body   *bytes.Buffer
+    status int
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+    r.body.Write(data)
+    return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+    r.body.WriteString(s)
+    return r.ResponseWriter.WriteString(s)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+    r.status = status
+    r.ResponseWriter.WriteHeader(status)
+}
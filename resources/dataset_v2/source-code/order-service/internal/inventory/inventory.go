@@ -0,0 +1,173 @@
+// Package inventory implements distributed stock reservation: Redis
+// holds a soft, TTL-bounded hold against a product's stock for the
+// duration of an in-flight order, and MongoDB remains the source of
+// truth for a product's actual stock level, only decremented once a
+// reservation commits. This replaces a plain check-then-update of
+// MongoDB stock, which let two concurrent orders both pass the check
+// and oversell the same units.
+package inventory
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "strconv"
+    "time"
+    "github.com/go-redis/redis/v8"
+)
+
+// ErrInsufficientStock is returned by ReserveInventory when fewer than
+// the requested quantity of a product are available.
+var ErrInsufficientStock = errors.New("inventory: insufficient stock")
+
+const (
+    stockKeyPrefix       = "stock:"
+    reservationKeyPrefix = "reservation:"
+    pendingReservationsKey = "reservations:pending"
+)
+
+// reserveScript atomically checks stock:<productID> against the
+// requested quantity, decrements it, records the hold in the
+// reservation hash, and schedules the hold for Reap via the pending
+// sorted set. KEYS: 1=stock key, 2=reservation key, 3=pending set.
+// ARGV: 1=qty, 2=productID, 3=expiresAtUnix, 4=reservationID.
+var reserveScript = redis.NewScript(`
+local stock = tonumber(redis.call('GET', KEYS[1]) or '0')
+local qty = tonumber(ARGV[1])
+if stock < qty then
+  return 0
+end
+redis.call('DECRBY', KEYS[1], qty)
+redis.call('HSET', KEYS[2], ARGV[2], qty)
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[4])
+return 1
+`)
+
+// releaseItemScript gives qty back to stock:<productID>. KEYS: 1=stock
+// key. ARGV: 1=qty.
+var releaseItemScript = redis.NewScript(`
+redis.call('INCRBY', KEYS[1], ARGV[1])
+return 1
+`)
+
+// cleanupScript removes a reservation's bookkeeping once it has been
+// committed or released. KEYS: 1=reservation key, 2=pending set.
+// ARGV: 1=reservationID.
+var cleanupScript = redis.NewScript(`
+redis.call('DEL', KEYS[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return 1
+`)
+
+// Repository persists the authoritative stock level for a product.
+// It's only written to at commit time; Redis holds the soft
+// reservation for the lifetime of an in-flight order.
+type Repository interface {
+    // Decrement adjusts productID's stock by -qty (negative qty
+    // increments it back).
+    Decrement(ctx context.Context, productID string, qty int) error
+}
+
+// Service implements ReserveInventory/CommitReservation/
+// ReleaseReservation on top of a Redis client and a Repository, plus a
+// Reap loop that reconciles reservations abandoned by a crash.
+type Service struct {
+    client     *redis.Client
+    repository Repository
+    logger     *slog.Logger
+}
+
+// NewService creates a Service and registers its Lua scripts with
+// Redis via SCRIPT LOAD, so ReserveInventory and friends can EVALSHA
+// rather than resending the source on every call.
+func NewService(ctx context.Context, client *redis.Client, repository Repository, logger *slog.Logger) (*Service, error) {
+    for _, script := range []*redis.Script{reserveScript, releaseItemScript, cleanupScript} {
+        if err := script.Load(ctx, client).Err(); err != nil {
+            return nil, fmt.Errorf("inventory: load script: %w", err)
+        }
+    }
+    return &Service{client: client, repository: repository, logger: logger}, nil
+}
+
+// ReserveInventory atomically checks and decrements stock:<productID>
+// by qty, recording the hold under reservationID until ttl elapses.
+// Calling it again with the same reservationID for another product
+// adds to that reservation's item set, so CommitReservation and
+// ReleaseReservation act on every item reserved so far. Returns
+// ErrInsufficientStock if fewer than qty units are available.
+func (s *Service) ReserveInventory(ctx context.Context, productID string, qty int, reservationID string, ttl time.Duration) error {
+    result, err := reserveScript.Run(ctx, s.client,
+        []string{stockKey(productID), reservationKey(reservationID), pendingReservationsKey},
+        qty, productID, time.Now().Add(ttl).Unix(), reservationID,
+    ).Int()
+    if err != nil {
+        return fmt.Errorf("inventory: reserve %s: %w", productID, err)
+    }
+    if result == 0 {
+        return ErrInsufficientStock
+    }
+    return nil
+}
+
+// CommitReservation persists every item held under reservationID as a
+// permanent MongoDB stock decrement, then clears the Redis hold.
+func (s *Service) CommitReservation(ctx context.Context, reservationID string) error {
+    items, err := s.client.HGetAll(ctx, reservationKey(reservationID)).Result()
+    if err != nil {
+        return fmt.Errorf("inventory: load reservation %s: %w", reservationID, err)
+    }
+
+    for productID, qtyStr := range items {
+        qty, err := strconv.Atoi(qtyStr)
+        if err != nil {
+            continue
+        }
+        if err := s.repository.Decrement(ctx, productID, qty); err != nil {
+            return fmt.Errorf("inventory: commit %s for reservation %s: %w", productID, reservationID, err)
+        }
+    }
+
+    return s.cleanup(ctx, reservationID)
+}
+
+// ReleaseReservation gives every item held under reservationID back to
+// Redis stock without ever touching MongoDB, since the hold was never
+// applied there.
+func (s *Service) ReleaseReservation(ctx context.Context, reservationID string) error {
+    items, err := s.client.HGetAll(ctx, reservationKey(reservationID)).Result()
+    if err != nil {
+        return fmt.Errorf("inventory: load reservation %s: %w", reservationID, err)
+    }
+
+    for productID, qtyStr := range items {
+        qty, err := strconv.Atoi(qtyStr)
+        if err != nil {
+            continue
+        }
+        if err := releaseItemScript.Run(ctx, s.client, []string{stockKey(productID)}, qty).Err(); err != nil {
+            return fmt.Errorf("inventory: release %s for reservation %s: %w", productID, reservationID, err)
+        }
+    }
+
+    return s.cleanup(ctx, reservationID)
+}
+
+// Refund reverses a previously committed decrement of qty units of
+// productID in MongoDB, used to compensate a CommitReservation whose
+// saga later failed to persist the order.
+func (s *Service) Refund(ctx context.Context, productID string, qty int) error {
+    return s.repository.Decrement(ctx, productID, -qty)
+}
+
+func (s *Service) cleanup(ctx context.Context, reservationID string) error {
+    return cleanupScript.Run(ctx, s.client, []string{reservationKey(reservationID), pendingReservationsKey}, reservationID).Err()
+}
+
+func stockKey(productID string) string {
+    return stockKeyPrefix + productID
+}
+
+func reservationKey(reservationID string) string {
+    return reservationKeyPrefix + reservationID
+}
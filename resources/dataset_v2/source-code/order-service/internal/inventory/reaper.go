@@ -0,0 +1,45 @@
+package inventory
+
+import (
+    "context"
+    "fmt"
+    "time"
+    "github.com/go-redis/redis/v8"
+)
+
+// Reap runs until ctx is cancelled, periodically reconciling any
+// reservation whose TTL elapsed without being committed or released
+// (e.g. the process crashed mid-order) back to available Redis stock.
+// interval should be comfortably shorter than the shortest reservation
+// TTL in use, so an abandoned hold isn't left blocking stock for long.
+func (s *Service) Reap(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            s.reapOnce(ctx)
+        }
+    }
+}
+
+func (s *Service) reapOnce(ctx context.Context) {
+    expired, err := s.client.ZRangeByScore(ctx, pendingReservationsKey, &redis.ZRangeBy{
+        Min: "-inf",
+        Max: fmt.Sprintf("%d", time.Now().Unix()),
+    }).Result()
+    if err != nil {
+        s.logger.ErrorContext(ctx, "inventory reaper: failed to list expired reservations", "error", err)
+        return
+    }
+
+    for _, reservationID := range expired {
+        s.logger.WarnContext(ctx, "inventory reaper: reconciling abandoned reservation", "reservation_id", reservationID)
+        if err := s.ReleaseReservation(ctx, reservationID); err != nil {
+            s.logger.ErrorContext(ctx, "inventory reaper: failed to release reservation", "reservation_id", reservationID, "error", err)
+        }
+    }
+}
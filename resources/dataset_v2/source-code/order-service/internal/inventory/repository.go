@@ -0,0 +1,27 @@
+package inventory
+
+import (
+    "context"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoRepository is the production Repository, backed by the "stock"
+// field of the "products" collection.
+type MongoRepository struct {
+    collection *mongo.Collection
+}
+
+// NewMongoRepository creates a MongoRepository using the "products"
+// collection of the "ecommerce" database.
+func NewMongoRepository(client *mongo.Client) *MongoRepository {
+    return &MongoRepository{collection: client.Database("ecommerce").Collection("products")}
+}
+
+func (r *MongoRepository) Decrement(ctx context.Context, productID string, qty int) error {
+    _, err := r.collection.UpdateOne(ctx,
+        bson.M{"_id": productID},
+        bson.M{"$inc": bson.M{"stock": -qty}},
+    )
+    return err
+}
@@ -0,0 +1,45 @@
+package observability
+
+import (
+    "context"
+    "log/slog"
+    "os"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+// NewLogger returns a JSON slog.Logger whose handler attaches trace_id
+// and span_id attributes from the context of each log call, so a log
+// line can be correlated with the span it was emitted from in
+// Jaeger/Tempo.
+func NewLogger() *slog.Logger {
+    return slog.New(&traceContextHandler{next: slog.NewJSONHandler(os.Stdout, nil)})
+}
+
+// traceContextHandler wraps an slog.Handler, adding trace_id/span_id
+// attributes drawn from the active span in the record's context.
+type traceContextHandler struct {
+    next slog.Handler
+}
+
+func (h *traceContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+    return h.next.Enabled(ctx, level)
+}
+
+func (h *traceContextHandler) Handle(ctx context.Context, record slog.Record) error {
+    if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+        record.AddAttrs(
+            slog.String("trace_id", span.SpanContext().TraceID().String()),
+            slog.String("span_id", span.SpanContext().SpanID().String()),
+        )
+    }
+    return h.next.Handle(ctx, record)
+}
+
+func (h *traceContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    return &traceContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *traceContextHandler) WithGroup(name string) slog.Handler {
+    return &traceContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,53 @@
+// Package observability wires up OpenTelemetry tracing and structured,
+// trace-aware logging shared across the order service's layers.
+package observability
+
+import (
+    "context"
+    "fmt"
+    "os"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// InitTracer configures the global TracerProvider and W3C trace context
+// propagator for serviceName. The OTLP/gRPC exporter target is read from
+// the standard OTEL_EXPORTER_OTLP_ENDPOINT env var (defaulting to
+// localhost:4317), so operators can point it at a local Jaeger/Tempo
+// collector without a code change. The returned shutdown func flushes
+// and closes the exporter; callers should defer it.
+func InitTracer(ctx context.Context, serviceName string) (shutdown func(context.Context) error, err error) {
+    endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+    if endpoint == "" {
+        endpoint = "localhost:4317"
+    }
+
+    exporter, err := otlptracegrpc.New(ctx,
+        otlptracegrpc.WithEndpoint(endpoint),
+        otlptracegrpc.WithInsecure(),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+    }
+
+    res, err := resource.Merge(resource.Default(),
+        resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+    if err != nil {
+        return nil, fmt.Errorf("build trace resource: %w", err)
+    }
+
+    tracerProvider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+    )
+
+    otel.SetTracerProvider(tracerProvider)
+    otel.SetTextMapPropagator(propagation.TraceContext{})
+
+    return tracerProvider.Shutdown, nil
+}
@@ -0,0 +1,165 @@
+// Package handler implements the Gin HTTP transport for order
+// management, delegating all business logic to service.OrderService.
+package handler
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "order-service/internal/order/model"
+    "order-service/internal/order/service"
+)
+
+/**
+ * HTTP handlers for order management operations.
+ * Implements RESTful API endpoints for order lifecycle management.
+ * All persistence and external integration is delegated to OrderService.
+ */
+type OrderHandler struct {
+    orderService *service.OrderService
+}
+
+// NewOrderHandler creates an OrderHandler backed by orderService.
+func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+    return &OrderHandler{orderService: orderService}
+}
+
+/**
+ * Creates a new order in the system.
+ * Validates user authentication, processes payment, and updates inventory.
+ * Returns order confirmation with tracking number.
+ *
+ * @param c Gin context containing request data
+ * @return JSON response with created order details
+ */
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+    var orderRequest model.CreateOrderRequest
+
+    if err := c.ShouldBindJSON(&orderRequest); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        return
+    }
+
+    // Extract user ID from JWT token (implementation simplified)
+    userID, exists := c.Get("userID")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+        return
+    }
+
+    order, err := h.orderService.CreateOrder(c.Request.Context(), userID.(string), orderRequest)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, order)
+}
+
+/**
+ * Retrieves order details by order ID.
+ * Checks the cache first, then falls back to MongoDB.
+ * Includes order items, payment status, and shipping information.
+ */
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+    orderID := c.Param("id")
+
+    objectID, err := primitive.ObjectIDFromHex(orderID)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+        return
+    }
+
+    order, err := h.orderService.GetOrder(c.Request.Context(), objectID)
+    if err != nil {
+        if err == mongo.ErrNoDocuments {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        return
+    }
+
+    c.JSON(http.StatusOK, order)
+}
+
+/**
+ * Updates order status (PENDING -> PAID -> SHIPPED -> DELIVERED).
+ * Validates status transitions and triggers appropriate notifications.
+ * Only authorized users can update order status.
+ */
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+    orderID := c.Param("id")
+
+    var statusUpdate model.StatusUpdateRequest
+    if err := c.ShouldBindJSON(&statusUpdate); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status update format"})
+        return
+    }
+
+    objectID, err := primitive.ObjectIDFromHex(orderID)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+        return
+    }
+
+    updatedOrder, err := h.orderService.UpdateOrderStatus(c.Request.Context(), objectID, statusUpdate.CurrentStatus, statusUpdate.NewStatus)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, updatedOrder)
+}
+
+/**
+ * Retrieves order history for a specific user.
+ * Supports pagination and filtering by order status.
+ * Results are sorted by creation date (newest first).
+ */
+func (h *OrderHandler) GetUserOrders(c *gin.Context) {
+    userID := c.Param("userId")
+
+    page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+    limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+    status := c.Query("status")
+
+    if page < 1 {
+        page = 1
+    }
+    if limit < 1 || limit > 100 {
+        limit = 10 // Max 100 orders per request
+    }
+
+    orders, total, err := h.orderService.GetUserOrders(c.Request.Context(), userID, page, limit, status)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "orders": orders,
+        "pagination": gin.H{
+            "page":  page,
+            "limit": limit,
+            "total": total,
+            "pages": (total + int64(limit) - 1) / int64(limit),
+        },
+    })
+}
+
+func (h *OrderHandler) GetOrderAnalytics(c *gin.Context) {
+    startDate := c.DefaultQuery("startDate", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
+    endDate := c.DefaultQuery("endDate", time.Now().Format("2006-01-02"))
+
+    analytics, err := h.orderService.GetOrderAnalytics(c.Request.Context(), startDate, endDate)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, analytics)
+}
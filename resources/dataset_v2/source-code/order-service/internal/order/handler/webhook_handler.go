@@ -0,0 +1,66 @@
+package handler
+
+import (
+    "io"
+    "net/http"
+    "order-service/internal/order/service"
+)
+
+// WebhookHandler verifies and applies inbound payment provider webhooks
+// against orders by their stored charge ID.
+type WebhookHandler struct {
+    orderService *service.OrderService
+    stripe       service.PaymentProvider
+    paypal       service.PaymentProvider
+}
+
+// NewWebhookHandler creates a WebhookHandler for the given providers.
+func NewWebhookHandler(orderService *service.OrderService, stripe, paypal service.PaymentProvider) *WebhookHandler {
+    return &WebhookHandler{orderService: orderService, stripe: stripe, paypal: paypal}
+}
+
+// HandleStripeWebhook verifies and applies a Stripe webhook event.
+func (wh *WebhookHandler) HandleStripeWebhook(w http.ResponseWriter, r *http.Request) {
+    wh.handleWebhook(w, r, wh.stripe, r.Header.Get("Stripe-Signature"))
+}
+
+// HandlePayPalWebhook verifies and applies a PayPal webhook event.
+func (wh *WebhookHandler) HandlePayPalWebhook(w http.ResponseWriter, r *http.Request) {
+    wh.handleWebhook(w, r, wh.paypal, r.Header.Get("Paypal-Transmission-Sig"))
+}
+
+func (wh *WebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request, provider service.PaymentProvider, signature string) {
+    body, err := io.ReadAll(r.Body)
+    if err != nil {
+        http.Error(w, "failed to read request body", http.StatusBadRequest)
+        return
+    }
+
+    event, err := provider.VerifyWebhook(body, signature)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    newStatus, ok := map[string]string{
+        "PAYMENT_COMPLETED": "PAID",
+        "PAYMENT_REFUNDED":  "REFUNDED",
+    }[event.Type]
+    if !ok {
+        http.Error(w, "unsupported event type", http.StatusBadRequest)
+        return
+    }
+
+    order, err := wh.orderService.GetOrderByChargeID(r.Context(), event.ChargeID)
+    if err != nil {
+        http.Error(w, "order not found for charge", http.StatusNotFound)
+        return
+    }
+
+    if _, err := wh.orderService.UpdateOrderStatus(r.Context(), order.ID, order.Status, newStatus); err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "order-service/internal/webhook"
+)
+
+// WebhookSubscriptionHandler implements the Gin transport for managing
+// webhook subscriptions and inspecting their delivery audit trail. Not
+// to be confused with WebhookHandler, which handles inbound payment
+// provider webhooks; this one is for outbound order lifecycle events.
+type WebhookSubscriptionHandler struct {
+    webhooks *webhook.Service
+}
+
+// NewWebhookSubscriptionHandler creates a WebhookSubscriptionHandler
+// backed by webhooks.
+func NewWebhookSubscriptionHandler(webhooks *webhook.Service) *WebhookSubscriptionHandler {
+    return &WebhookSubscriptionHandler{webhooks: webhooks}
+}
+
+// subscriptionRequest is the request body for registering a subscription.
+type subscriptionRequest struct {
+    URL    string   `json:"url" binding:"required"`
+    Events []string `json:"events" binding:"required"`
+    Secret string   `json:"secret" binding:"required"`
+}
+
+// CreateSubscription handles POST /api/webhooks.
+func (h *WebhookSubscriptionHandler) CreateSubscription(c *gin.Context) {
+    var req subscriptionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        return
+    }
+
+    sub := &webhook.Subscription{URL: req.URL, Events: req.Events, Secret: req.Secret, Active: true}
+    if err := h.webhooks.CreateSubscription(c.Request.Context(), sub); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, sub)
+}
+
+// ListSubscriptions handles GET /api/webhooks.
+func (h *WebhookSubscriptionHandler) ListSubscriptions(c *gin.Context) {
+    subs, err := h.webhooks.ListSubscriptions(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+// DeleteSubscription handles DELETE /api/webhooks/:id.
+func (h *WebhookSubscriptionHandler) DeleteSubscription(c *gin.Context) {
+    id, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID format"})
+        return
+    }
+
+    if err := h.webhooks.DeleteSubscription(c.Request.Context(), id); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/webhooks/:id/deliveries, the audit
+// trail of every delivery attempted or scheduled for a subscription.
+func (h *WebhookSubscriptionHandler) ListDeliveries(c *gin.Context) {
+    id, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID format"})
+        return
+    }
+
+    deliveries, err := h.webhooks.Deliveries(c.Request.Context(), id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
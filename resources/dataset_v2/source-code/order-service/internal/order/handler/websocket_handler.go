@@ -0,0 +1,80 @@
+package handler
+
+import (
+    "context"
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "github.com/gorilla/websocket"
+    "order-service/internal/order/service"
+)
+
+/**
+ * WebSocket transport for real-time order updates. Lets authenticated
+ * clients subscribe to their own order lifecycle events instead of
+ * polling GetOrder.
+ */
+
+var wsUpgrader = websocket.Upgrader{
+    ReadBufferSize:  1024,
+    WriteBufferSize: 1024,
+    // Every origin is accepted; the CORS middleware in main.go allows
+    // all origins as well.
+    CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocketHandler serves the /ws/orders subscription endpoint.
+type WebSocketHandler struct {
+    pubsub service.PubSub
+}
+
+// NewWebSocketHandler creates a WebSocketHandler backed by pubsub.
+func NewWebSocketHandler(pubsub service.PubSub) *WebSocketHandler {
+    return &WebSocketHandler{pubsub: pubsub}
+}
+
+// SubscribeOrders upgrades the connection to a WebSocket and streams
+// OrderEvents published for the authenticated user until the client
+// disconnects.
+func (h *WebSocketHandler) SubscribeOrders(c *gin.Context) {
+    userID, exists := c.Get("userID")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+        return
+    }
+
+    conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade connection"})
+        return
+    }
+    defer conn.Close()
+
+    ctx, cancel := context.WithCancel(c.Request.Context())
+    defer cancel()
+
+    // Detect client-initiated disconnects so the subscription goroutine
+    // can be torn down promptly.
+    go func() {
+        defer cancel()
+        for {
+            if _, _, err := conn.ReadMessage(); err != nil {
+                return
+            }
+        }
+    }()
+
+    events := h.pubsub.Subscribe(ctx, service.UserOrdersChannel(userID.(string)))
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case event, ok := <-events:
+            if !ok {
+                return
+            }
+            if err := conn.WriteJSON(event); err != nil {
+                return
+            }
+        }
+    }
+}
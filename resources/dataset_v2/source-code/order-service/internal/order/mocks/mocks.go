@@ -0,0 +1,285 @@
+// Package mocks provides in-memory fakes for the order service's
+// dependency interfaces, so OrderService and OrderHandler can be tested
+// without a live MongoDB or Redis.
+package mocks
+
+import (
+    "context"
+    "sync"
+    "time"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "order-service/internal/order/model"
+    "order-service/pkg/saga"
+)
+
+// FakeOrderRepository is an in-memory OrderRepository.
+type FakeOrderRepository struct {
+    mu     sync.Mutex
+    orders map[primitive.ObjectID]model.Order
+}
+
+// NewFakeOrderRepository creates an empty FakeOrderRepository.
+func NewFakeOrderRepository() *FakeOrderRepository {
+    return &FakeOrderRepository{orders: make(map[primitive.ObjectID]model.Order)}
+}
+
+func (f *FakeOrderRepository) Insert(ctx context.Context, order *model.Order) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.orders[order.ID] = *order
+    return nil
+}
+
+func (f *FakeOrderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Order, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    order, ok := f.orders[id]
+    if !ok {
+        return nil, mongo.ErrNoDocuments
+    }
+    return &order, nil
+}
+
+func (f *FakeOrderRepository) FindByChargeID(ctx context.Context, chargeID string) (*model.Order, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    for _, order := range f.orders {
+        if order.PaymentID == chargeID {
+            return &order, nil
+        }
+    }
+    return nil, mongo.ErrNoDocuments
+}
+
+func (f *FakeOrderRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, newStatus string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    order, ok := f.orders[id]
+    if !ok {
+        return mongo.ErrNoDocuments
+    }
+    order.Status = newStatus
+    f.orders[id] = order
+    return nil
+}
+
+func (f *FakeOrderRepository) FindByUser(ctx context.Context, userID string, page, limit int, status string) ([]model.Order, int64, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    var matched []model.Order
+    for _, order := range f.orders {
+        if order.UserID != userID {
+            continue
+        }
+        if status != "" && order.Status != status {
+            continue
+        }
+        matched = append(matched, order)
+    }
+
+    total := int64(len(matched))
+    start := (page - 1) * limit
+    if start > len(matched) {
+        start = len(matched)
+    }
+    end := start + limit
+    if end > len(matched) {
+        end = len(matched)
+    }
+
+    return matched[start:end], total, nil
+}
+
+// FakeNotificationPublisher records every notification it's asked to publish.
+type FakeNotificationPublisher struct {
+    mu    sync.Mutex
+    Tasks []model.NotificationTask
+    Err   error
+}
+
+func NewFakeNotificationPublisher() *FakeNotificationPublisher {
+    return &FakeNotificationPublisher{}
+}
+
+func (f *FakeNotificationPublisher) Publish(ctx context.Context, kind string, task model.NotificationTask) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.Tasks = append(f.Tasks, task)
+    return f.Err
+}
+
+// FakePaymentProvider is a PaymentProvider that always succeeds unless
+// configured otherwise via Err.
+type FakePaymentProvider struct {
+    Err error
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+    return &FakePaymentProvider{}
+}
+
+func (f *FakePaymentProvider) CreateCharge(ctx context.Context, orderID, paymentMethod string, amount float64) (*model.PaymentCharge, error) {
+    if f.Err != nil {
+        return nil, f.Err
+    }
+    return &model.PaymentCharge{ChargeID: "charge_" + orderID, Status: "PENDING"}, nil
+}
+
+func (f *FakePaymentProvider) RefundCharge(ctx context.Context, chargeID string) error {
+    return f.Err
+}
+
+func (f *FakePaymentProvider) VerifyWebhook(payload []byte, signatureHeader string) (*model.WebhookEvent, error) {
+    return nil, f.Err
+}
+
+// FakePubSub is a PubSub that records published events without requiring Redis.
+type FakePubSub struct {
+    mu     sync.Mutex
+    Events []model.OrderEvent
+}
+
+func NewFakePubSub() *FakePubSub {
+    return &FakePubSub{}
+}
+
+func (f *FakePubSub) Publish(ctx context.Context, channel string, event model.OrderEvent) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.Events = append(f.Events, event)
+    return nil
+}
+
+func (f *FakePubSub) Subscribe(ctx context.Context, channel string) <-chan model.OrderEvent {
+    ch := make(chan model.OrderEvent)
+    close(ch)
+    return ch
+}
+
+// FakeSagaStore is an in-memory saga.Store.
+type FakeSagaStore struct {
+    mu     sync.Mutex
+    states map[primitive.ObjectID]saga.State
+}
+
+// NewFakeSagaStore creates an empty FakeSagaStore.
+func NewFakeSagaStore() *FakeSagaStore {
+    return &FakeSagaStore{states: make(map[primitive.ObjectID]saga.State)}
+}
+
+func (f *FakeSagaStore) Create(ctx context.Context, state *saga.State) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.states[state.ID] = *state
+    return nil
+}
+
+func (f *FakeSagaStore) Update(ctx context.Context, state *saga.State) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.states[state.ID] = *state
+    return nil
+}
+
+func (f *FakeSagaStore) FindNonTerminal(ctx context.Context) ([]saga.State, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    var pending []saga.State
+    for _, state := range f.states {
+        if state.Status != saga.StatusCompleted && state.Status != saga.StatusCompensated {
+            pending = append(pending, state)
+        }
+    }
+    return pending, nil
+}
+
+// FakeInventory is an in-memory service.Inventory that always has
+// stock, recording reservations so tests can assert on commit/release
+// behavior without a live Redis or MongoDB.
+type FakeInventory struct {
+    mu           sync.Mutex
+    Reservations map[string]map[string]int // reservationID -> productID -> qty
+    Refunds      map[string]int            // productID -> total refunded qty
+}
+
+// NewFakeInventory creates an empty FakeInventory.
+func NewFakeInventory() *FakeInventory {
+    return &FakeInventory{
+        Reservations: make(map[string]map[string]int),
+        Refunds:      make(map[string]int),
+    }
+}
+
+func (f *FakeInventory) ReserveInventory(ctx context.Context, productID string, qty int, reservationID string, ttl time.Duration) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.Reservations[reservationID] == nil {
+        f.Reservations[reservationID] = make(map[string]int)
+    }
+    f.Reservations[reservationID][productID] = qty
+    return nil
+}
+
+func (f *FakeInventory) CommitReservation(ctx context.Context, reservationID string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    delete(f.Reservations, reservationID)
+    return nil
+}
+
+func (f *FakeInventory) ReleaseReservation(ctx context.Context, reservationID string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    delete(f.Reservations, reservationID)
+    return nil
+}
+
+func (f *FakeInventory) Refund(ctx context.Context, productID string, qty int) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.Refunds[productID] += qty
+    return nil
+}
+
+// FakeWebhooks is an in-memory service.Webhooks that records every
+// event emitted to it.
+type FakeWebhooks struct {
+    mu     sync.Mutex
+    Events []FakeWebhookEvent
+}
+
+// FakeWebhookEvent is one call recorded by FakeWebhooks.Emit.
+type FakeWebhookEvent struct {
+    Type    string
+    OrderID string
+    Payload interface{}
+}
+
+// NewFakeWebhooks creates an empty FakeWebhooks.
+func NewFakeWebhooks() *FakeWebhooks {
+    return &FakeWebhooks{}
+}
+
+func (f *FakeWebhooks) Emit(ctx context.Context, eventType, orderID string, payload interface{}) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.Events = append(f.Events, FakeWebhookEvent{Type: eventType, OrderID: orderID, Payload: payload})
+    return nil
+}
+
+// FakeTxRunner is a service.TxRunner that runs fn directly against ctx,
+// without any real transactional isolation, since the fakes it's used
+// alongside in tests aren't transactional either.
+type FakeTxRunner struct{}
+
+// NewFakeTxRunner creates a FakeTxRunner.
+func NewFakeTxRunner() *FakeTxRunner {
+    return &FakeTxRunner{}
+}
+
+func (f *FakeTxRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    return fn(ctx)
+}
@@ -0,0 +1,85 @@
+// Package model holds the domain types shared by the order service's
+// handler, service, and repository layers.
+package model
+
+import (
+    "encoding/json"
+    "time"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Order represents a customer order and its current lifecycle status.
+type Order struct {
+    ID          primitive.ObjectID `json:"id" bson:"_id"`
+    UserID      string             `json:"userId" bson:"userId"`
+    Items       []Item             `json:"items" bson:"items"`
+    TotalAmount float64            `json:"totalAmount" bson:"totalAmount"`
+    Status      string             `json:"status" bson:"status"`
+    PaymentID   string             `json:"paymentId" bson:"paymentId"`
+    CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+    UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Item is a single line item within an order.
+type Item struct {
+    ProductID string  `json:"productId" bson:"productId"`
+    Quantity  int     `json:"quantity" bson:"quantity"`
+    UnitPrice float64 `json:"unitPrice" bson:"unitPrice"`
+}
+
+// CreateOrderRequest is the request body for creating a new order.
+type CreateOrderRequest struct {
+    Items         []Item  `json:"items"`
+    TotalAmount   float64 `json:"totalAmount"`
+    PaymentMethod string  `json:"paymentMethod"`
+}
+
+// StatusUpdateRequest is the request body for transitioning an order's status.
+type StatusUpdateRequest struct {
+    CurrentStatus string `json:"currentStatus"`
+    NewStatus     string `json:"newStatus"`
+}
+
+// OrderEvent describes a single order lifecycle transition broadcast to
+// subscribers of a user's order channel.
+type OrderEvent struct {
+    OrderID   string    `json:"orderId"`
+    UserID    string    `json:"userId"`
+    OldStatus string    `json:"oldStatus"`
+    NewStatus string    `json:"newStatus"`
+    Timestamp time.Time `json:"timestamp"`
+}
+
+// NotificationTask is the payload enqueued for notification delivery.
+type NotificationTask struct {
+    Event   string `json:"event"`
+    OrderID string `json:"orderId"`
+    UserID  string `json:"userId"`
+    Status  string `json:"status,omitempty"`
+}
+
+// PaymentCharge is the result of authorizing a charge with a payment provider.
+type PaymentCharge struct {
+    ChargeID string
+    Status   string
+}
+
+// WebhookEvent is the normalized shape a payment provider's webhook is
+// translated into before being applied to an order.
+type WebhookEvent struct {
+    Type     string // e.g. "PAYMENT_COMPLETED", "PAYMENT_REFUNDED"
+    ChargeID string
+}
+
+// Task is a unit of work enqueued onto the durable job queue.
+type Task struct {
+    ID         string          `json:"id"`
+    Kind       string          `json:"kind"`
+    Payload    json.RawMessage `json:"payload"`
+    Attempts   int             `json:"attempts"`
+    EnqueuedAt time.Time       `json:"enqueuedAt"`
+    // TraceParent carries the W3C traceparent header of the request that
+    // enqueued this task, so the worker that eventually processes it can
+    // link its span back to the originating request trace.
+    TraceParent string `json:"traceParent,omitempty"`
+}
@@ -0,0 +1,90 @@
+// Package repository implements persistence for orders.
+package repository
+
+import (
+    "context"
+    "time"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "order-service/internal/order/model"
+)
+
+// OrderRepository is the persistence boundary OrderService depends on.
+// Implementations must be safe for concurrent use.
+type OrderRepository interface {
+    Insert(ctx context.Context, order *model.Order) error
+    FindByID(ctx context.Context, id primitive.ObjectID) (*model.Order, error)
+    FindByChargeID(ctx context.Context, chargeID string) (*model.Order, error)
+    UpdateStatus(ctx context.Context, id primitive.ObjectID, newStatus string) error
+    FindByUser(ctx context.Context, userID string, page, limit int, status string) ([]model.Order, int64, error)
+}
+
+// MongoOrderRepository is the production OrderRepository backed by MongoDB.
+type MongoOrderRepository struct {
+    collection *mongo.Collection
+}
+
+// NewMongoOrderRepository creates a MongoOrderRepository using the
+// "orders" collection of the "ecommerce" database.
+func NewMongoOrderRepository(client *mongo.Client) *MongoOrderRepository {
+    return &MongoOrderRepository{collection: client.Database("ecommerce").Collection("orders")}
+}
+
+func (r *MongoOrderRepository) Insert(ctx context.Context, order *model.Order) error {
+    _, err := r.collection.InsertOne(ctx, order)
+    return err
+}
+
+func (r *MongoOrderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Order, error) {
+    var order model.Order
+    if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&order); err != nil {
+        return nil, err
+    }
+    return &order, nil
+}
+
+func (r *MongoOrderRepository) FindByChargeID(ctx context.Context, chargeID string) (*model.Order, error) {
+    var order model.Order
+    if err := r.collection.FindOne(ctx, bson.M{"paymentId": chargeID}).Decode(&order); err != nil {
+        return nil, err
+    }
+    return &order, nil
+}
+
+func (r *MongoOrderRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, newStatus string) error {
+    update := bson.M{"$set": bson.M{"status": newStatus, "updatedAt": time.Now()}}
+    _, err := r.collection.UpdateByID(ctx, id, update)
+    return err
+}
+
+func (r *MongoOrderRepository) FindByUser(ctx context.Context, userID string, page, limit int, status string) ([]model.Order, int64, error) {
+    filter := bson.M{"userId": userID}
+    if status != "" {
+        filter["status"] = status
+    }
+
+    total, err := r.collection.CountDocuments(ctx, filter)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    opts := options.Find().
+        SetSort(bson.M{"createdAt": -1}).
+        SetSkip(int64((page - 1) * limit)).
+        SetLimit(int64(limit))
+
+    cursor, err := r.collection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer cursor.Close(ctx)
+
+    var orders []model.Order
+    if err := cursor.All(ctx, &orders); err != nil {
+        return nil, 0, err
+    }
+
+    return orders, total, nil
+}
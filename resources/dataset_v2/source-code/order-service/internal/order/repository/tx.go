@@ -0,0 +1,34 @@
+package repository
+
+import (
+    "context"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoTxRunner runs a function inside a single Mongo client session
+// transaction, for callers that need two otherwise-independent writes
+// (e.g. an order mutation and the webhook outbox event describing it)
+// to commit or roll back together.
+type MongoTxRunner struct {
+    client *mongo.Client
+}
+
+// NewMongoTxRunner creates a MongoTxRunner using client.
+func NewMongoTxRunner(client *mongo.Client) *MongoTxRunner {
+    return &MongoTxRunner{client: client}
+}
+
+// WithTransaction runs fn with a session-bound context, committing the
+// session if fn returns nil and aborting it otherwise.
+func (r *MongoTxRunner) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    session, err := r.client.StartSession()
+    if err != nil {
+        return err
+    }
+    defer session.EndSession(ctx)
+
+    _, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+        return nil, fn(sessCtx)
+    })
+    return err
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "time"
+    "github.com/go-redis/redis/v8"
+    "golang.org/x/sync/singleflight"
+    "order-service/internal/order/model"
+)
+
+/**
+ * Typed Redis cache for Order documents. JSON-encodes on Set and decodes
+ * into a typed *model.Order on Get, so GetOrder returns the same response
+ * shape whether it's a cache hit or a MongoDB fallback. GetOrLoad also
+ * collapses concurrent lookups for the same key into a single MongoDB
+ * fetch (singleflight) to avoid a stampede on a hot order ID.
+ */
+
+// ErrCacheMiss indicates the key was not present in the cache.
+var ErrCacheMiss = errors.New("cache: key not found")
+
+// Cache is the caching boundary OrderService depends on, implemented by
+// *OrderCache in production and a fake in tests.
+type Cache interface {
+    Get(ctx context.Context, key string) (*model.Order, error)
+    Set(ctx context.Context, key string, order *model.Order, ttl time.Duration) error
+    GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func() (*model.Order, error)) (*model.Order, error)
+}
+
+// OrderCache is a JSON-encoding Redis cache for *model.Order values.
+type OrderCache struct {
+    client *redis.Client
+    group  singleflight.Group
+}
+
+// NewOrderCache creates an OrderCache backed by client.
+func NewOrderCache(client *redis.Client) *OrderCache {
+    return &OrderCache{client: client}
+}
+
+// Set JSON-encodes order and stores it under key with the given TTL.
+func (c *OrderCache) Set(ctx context.Context, key string, order *model.Order, ttl time.Duration) error {
+    encoded, err := json.Marshal(order)
+    if err != nil {
+        return err
+    }
+    return c.client.Set(ctx, key, encoded, ttl).Err()
+}
+
+// Get decodes the cached value for key into a *model.Order. It returns
+// ErrCacheMiss if the key isn't present.
+func (c *OrderCache) Get(ctx context.Context, key string) (*model.Order, error) {
+    raw, err := c.client.Get(ctx, key).Result()
+    if err == redis.Nil {
+        return nil, ErrCacheMiss
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var order model.Order
+    if err := json.Unmarshal([]byte(raw), &order); err != nil {
+        return nil, err
+    }
+    return &order, nil
+}
+
+// GetOrLoad returns the cached order for key, or calls load to fetch it
+// (typically from MongoDB) on a miss, caching the result with ttl for
+// subsequent lookups. Concurrent GetOrLoad calls for the same key share
+// a single in-flight load so a stampede of requests for a hot order ID
+// results in exactly one MongoDB query.
+func (c *OrderCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func() (*model.Order, error)) (*model.Order, error) {
+    if order, err := c.Get(ctx, key); err == nil {
+        return order, nil
+    }
+    return c.loadAndCache(ctx, key, ttl, load)
+}
+
+func (c *OrderCache) loadAndCache(ctx context.Context, key string, ttl time.Duration, load func() (*model.Order, error)) (*model.Order, error) {
+    result, err, _ := c.group.Do(key, func() (interface{}, error) {
+        order, err := load()
+        if err != nil {
+            return nil, err
+        }
+
+        if err := c.Set(ctx, key, order, ttl); err != nil {
+            // Cache population failures shouldn't fail the request; the
+            // next lookup will simply load from MongoDB again.
+            return order, nil
+        }
+
+        return order, nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result.(*model.Order), nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+    "context"
+    "time"
+)
+
+// Inventory is the distributed stock reservation boundary OrderService
+// depends on: a soft, TTL-bounded hold against Redis stock for the
+// duration of an in-flight order, committed to MongoDB on success or
+// released back to Redis on failure. See internal/inventory for the
+// production implementation.
+type Inventory interface {
+    ReserveInventory(ctx context.Context, productID string, qty int, reservationID string, ttl time.Duration) error
+    CommitReservation(ctx context.Context, reservationID string) error
+    ReleaseReservation(ctx context.Context, reservationID string) error
+    Refund(ctx context.Context, productID string, qty int) error
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+    "order-service/internal/order/model"
+)
+
+var notificationHTTPClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// DeliverNotification is the queue Handler that delivers a notification
+// task to the notification service. Returning an error causes the queue
+// to retry delivery with backoff. The HTTP call is made with taskCtx, so
+// its span is a child of the task's processing span set up by
+// Queue.process, preserving the trace back to the request that enqueued
+// the notification.
+func DeliverNotification(ctx context.Context, task model.Task) error {
+    var payload model.NotificationTask
+    if err := json.Unmarshal(task.Payload, &payload); err != nil {
+        return err
+    }
+
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost:8084/api/notifications", bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := notificationHTTPClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        return fmt.Errorf("notification service returned status %d", resp.StatusCode)
+    }
+
+    return nil
+}
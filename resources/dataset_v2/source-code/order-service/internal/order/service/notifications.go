@@ -0,0 +1,22 @@
+package service
+
+import (
+    "context"
+    "order-service/internal/order/model"
+)
+
+// QueueNotificationPublisher implements NotificationPublisher on top of
+// the durable job Queue, so a crash between order creation and
+// notification dispatch can't lose the event.
+type QueueNotificationPublisher struct {
+    queue *Queue
+}
+
+// NewQueueNotificationPublisher creates a NotificationPublisher backed by queue.
+func NewQueueNotificationPublisher(queue *Queue) *QueueNotificationPublisher {
+    return &QueueNotificationPublisher{queue: queue}
+}
+
+func (p *QueueNotificationPublisher) Publish(ctx context.Context, kind string, task model.NotificationTask) error {
+    return p.queue.Enqueue(ctx, kind, task)
+}
@@ -0,0 +1,393 @@
+// Package service implements the order service's business logic: order
+// creation and lifecycle transitions, payment authorization, caching,
+// and event publication. Apart from the saga.Coordinator that drives
+// CreateOrder, its dependencies (OrderRepository, NotificationPublisher,
+// PaymentProvider, Cache, PubSub, Inventory, Webhooks, TxRunner) are
+// interfaces rather than concrete transport or persistence types, so it
+// can be exercised in tests against fakes.
+package service
+
+import (
+    "context"
+    "fmt"
+    "time"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "order-service/internal/order/model"
+    "order-service/internal/order/repository"
+    "order-service/pkg/saga"
+)
+
+// createOrderSagaType identifies the CreateOrder saga to the
+// Coordinator, both for Run and for resuming it after a restart.
+const createOrderSagaType = "create_order"
+
+// reservationTTL bounds how long an inventory reservation can sit
+// un-committed and un-released before the inventory reaper reclaims it,
+// comfortably longer than payment authorization should ever take.
+const reservationTTL = 5 * time.Minute
+
+// NotificationPublisher delivers a notification task for asynchronous
+// processing (e.g. onto the durable job queue).
+type NotificationPublisher interface {
+    Publish(ctx context.Context, kind string, task model.NotificationTask) error
+}
+
+// OrderService contains the order lifecycle business logic. It is
+// transport- and persistence-agnostic: OrderHandler talks to it, it
+// talks to OrderRepository and NotificationPublisher.
+type OrderService struct {
+    repository      repository.OrderRepository
+    notifications   NotificationPublisher
+    paymentProvider PaymentProvider
+    cache           Cache
+    pubsub          PubSub
+    sagas           *saga.Coordinator
+    inventory       Inventory
+    webhooks        Webhooks
+    txRunner        TxRunner
+}
+
+// NewOrderService creates an OrderService wired to the given
+// dependencies and registers its CreateOrder saga with sagas, so that
+// Coordinator.Resume (called at startup) can replay any CreateOrder
+// left in-flight by a crash.
+func NewOrderService(repo repository.OrderRepository, notifications NotificationPublisher, paymentProvider PaymentProvider, cache Cache, pubsub PubSub, sagas *saga.Coordinator, inventory Inventory, webhooks Webhooks, txRunner TxRunner) *OrderService {
+    s := &OrderService{
+        repository:      repo,
+        notifications:   notifications,
+        paymentProvider: paymentProvider,
+        cache:           cache,
+        pubsub:          pubsub,
+        sagas:           sagas,
+        inventory:       inventory,
+        webhooks:        webhooks,
+        txRunner:        txRunner,
+    }
+    sagas.Register(createOrderSagaType, s.buildCreateOrderSteps)
+    return s
+}
+
+/**
+ * Creates a new order with payment processing, driven by the
+ * create-order saga (ReserveInventory → AuthorizePayment →
+ * CommitInventory → PersistOrder). If any step fails, the saga
+ * compensates every step that already succeeded (releasing the
+ * reservation, refunding the payment) before the error is returned, so
+ * a failed CreateOrder never leaves a paid-but-not-persisted order.
+ *
+ * @param userID User placing the order
+ * @param request Order creation request data
+ * @return Created order with generated tracking number
+ */
+func (s *OrderService) CreateOrder(ctx context.Context, userID string, request model.CreateOrderRequest) (*model.Order, error) {
+    data := bson.M{
+        "orderId":   primitive.NewObjectID().Hex(),
+        "userId":    userID,
+        "request":   request,
+        "status":    "PENDING",
+        "createdAt": time.Now(),
+    }
+
+    state, err := s.sagas.Run(ctx, createOrderSagaType, data)
+    if err != nil {
+        return nil, err
+    }
+
+    order, err := orderFromSagaData(state.Data)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.notifications.Publish(ctx, "order_created", model.NotificationTask{Event: "order_created", OrderID: order.ID.Hex(), UserID: order.UserID}); err != nil {
+        // Notification delivery is best-effort and must not fail order creation.
+        _ = err
+    }
+
+    s.publishOrderEvent(ctx, order, "", order.Status)
+
+    return order, nil
+}
+
+// buildCreateOrderSteps reconstructs the CreateOrder saga's steps from
+// its persisted Data. It is registered with the Coordinator under
+// createOrderSagaType, so the same builder both drives a fresh
+// CreateOrder call and lets Coordinator.Resume replay one recovered
+// after a crash.
+func (s *OrderService) buildCreateOrderSteps(data bson.M) []saga.Step {
+    return []saga.Step{
+        {
+            Name: "ReserveInventory",
+            Do: func(ctx context.Context, state *saga.State) error {
+                request, err := sagaRequest(state.Data)
+                if err != nil {
+                    return err
+                }
+                orderID, _ := state.Data["orderId"].(string)
+                for _, item := range request.Items {
+                    if err := s.inventory.ReserveInventory(ctx, item.ProductID, item.Quantity, orderID, reservationTTL); err != nil {
+                        return fmt.Errorf("reserve inventory for product %s: %w", item.ProductID, err)
+                    }
+                }
+                return nil
+            },
+            Compensate: func(ctx context.Context, state *saga.State) error {
+                orderID, _ := state.Data["orderId"].(string)
+                return s.inventory.ReleaseReservation(ctx, orderID)
+            },
+        },
+        {
+            Name: "AuthorizePayment",
+            Do: func(ctx context.Context, state *saga.State) error {
+                request, err := sagaRequest(state.Data)
+                if err != nil {
+                    return err
+                }
+                orderID, _ := state.Data["orderId"].(string)
+                charge, err := s.paymentProvider.CreateCharge(ctx, orderID, request.PaymentMethod, request.TotalAmount)
+                if err != nil {
+                    return fmt.Errorf("payment authorization failed: %w", err)
+                }
+                state.Data["chargeId"] = charge.ChargeID
+                state.Data["status"] = "PAID"
+                return nil
+            },
+            Compensate: func(ctx context.Context, state *saga.State) error {
+                chargeID, _ := state.Data["chargeId"].(string)
+                if chargeID == "" {
+                    return nil
+                }
+                return s.paymentProvider.RefundCharge(ctx, chargeID)
+            },
+        },
+        {
+            Name: "CommitInventory",
+            Do: func(ctx context.Context, state *saga.State) error {
+                orderID, _ := state.Data["orderId"].(string)
+                return s.inventory.CommitReservation(ctx, orderID)
+            },
+            Compensate: func(ctx context.Context, state *saga.State) error {
+                // The reservation's Redis hold is already gone (commit
+                // cleared it), so undo the permanent MongoDB decrement
+                // directly, item by item.
+                request, err := sagaRequest(state.Data)
+                if err != nil {
+                    return err
+                }
+                for _, item := range request.Items {
+                    if err := s.inventory.Refund(ctx, item.ProductID, item.Quantity); err != nil {
+                        return err
+                    }
+                }
+                return nil
+            },
+        },
+        {
+            Name: "PersistOrder",
+            Do: func(ctx context.Context, state *saga.State) error {
+                order, err := orderFromSagaData(state.Data)
+                if err != nil {
+                    return err
+                }
+                // The order insert and the order.created outbox event
+                // commit together, so a reader of the outbox never sees
+                // an event for an order that isn't actually there.
+                return s.txRunner.WithTransaction(ctx, func(txCtx context.Context) error {
+                    if err := s.repository.Insert(txCtx, order); err != nil {
+                        return err
+                    }
+                    return s.webhooks.Emit(txCtx, "order.created", order.ID.Hex(), order)
+                })
+            },
+            Compensate: func(ctx context.Context, state *saga.State) error {
+                order, err := orderFromSagaData(state.Data)
+                if err != nil {
+                    return err
+                }
+                return s.repository.UpdateStatus(ctx, order.ID, "CANCELLED")
+            },
+        },
+    }
+}
+
+// sagaRequest decodes the original CreateOrderRequest out of saga Data.
+// Data round-trips through BSON both when a saga runs fresh (request is
+// already a model.CreateOrderRequest) and when it's reloaded from
+// MongoDB for Coordinator.Resume (request is a generic bson.M), so
+// steps decode it the same way in both cases instead of type-asserting.
+func sagaRequest(data bson.M) (model.CreateOrderRequest, error) {
+    var request model.CreateOrderRequest
+    raw, err := bson.Marshal(data["request"])
+    if err != nil {
+        return request, fmt.Errorf("saga: encode request: %w", err)
+    }
+    if err := bson.Unmarshal(raw, &request); err != nil {
+        return request, fmt.Errorf("saga: decode request: %w", err)
+    }
+    return request, nil
+}
+
+// orderFromSagaData assembles the Order that a CreateOrder saga run has
+// built up so far from its Data.
+func orderFromSagaData(data bson.M) (*model.Order, error) {
+    request, err := sagaRequest(data)
+    if err != nil {
+        return nil, err
+    }
+
+    orderIDHex, _ := data["orderId"].(string)
+    orderID, err := primitive.ObjectIDFromHex(orderIDHex)
+    if err != nil {
+        return nil, fmt.Errorf("saga: decode order id: %w", err)
+    }
+
+    userID, _ := data["userId"].(string)
+    status, _ := data["status"].(string)
+    chargeID, _ := data["chargeId"].(string)
+
+    var createdAt time.Time
+    if raw, err := bson.Marshal(bson.M{"v": data["createdAt"]}); err == nil {
+        var wrapper struct {
+            V time.Time `bson:"v"`
+        }
+        if bson.Unmarshal(raw, &wrapper) == nil {
+            createdAt = wrapper.V
+        }
+    }
+
+    return &model.Order{
+        ID:          orderID,
+        UserID:      userID,
+        Items:       request.Items,
+        TotalAmount: request.TotalAmount,
+        Status:      status,
+        PaymentID:   chargeID,
+        CreatedAt:   createdAt,
+        UpdatedAt:   time.Now(),
+    }, nil
+}
+
+/**
+ * Retrieves order details by order ID. Checks the cache first, falling
+ * back to the repository on a miss. GetOrLoad collapses concurrent
+ * misses for the same order ID into a single repository fetch so a hot
+ * order doesn't cause a stampede.
+ */
+func (s *OrderService) GetOrder(ctx context.Context, orderID primitive.ObjectID) (*model.Order, error) {
+    cacheKey := "order:" + orderID.Hex()
+    return s.cache.GetOrLoad(ctx, cacheKey, time.Hour, func() (*model.Order, error) {
+        return s.repository.FindByID(ctx, orderID)
+    })
+}
+
+// GetOrderByChargeID looks up an order by the charge ID returned from
+// CreateOrder's payment authorization, so PayPal/Stripe webhooks can
+// resolve the order they pertain to.
+func (s *OrderService) GetOrderByChargeID(ctx context.Context, chargeID string) (*model.Order, error) {
+    return s.repository.FindByChargeID(ctx, chargeID)
+}
+
+/**
+ * Updates order status with validation of status transitions.
+ * Only allows valid status transitions based on business rules.
+ */
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID primitive.ObjectID, currentStatus, newStatus string) (*model.Order, error) {
+    order, err := s.repository.FindByID(ctx, orderID)
+    if err != nil {
+        return nil, err
+    }
+
+    if !s.IsValidStatusTransition(order.Status, newStatus) {
+        return nil, fmt.Errorf("invalid status transition from %s to %s", order.Status, newStatus)
+    }
+
+    // The status update and the webhook event(s) describing it commit
+    // together, for the same reason PersistOrder ties the insert and
+    // order.created together.
+    err = s.txRunner.WithTransaction(ctx, func(txCtx context.Context) error {
+        if err := s.repository.UpdateStatus(txCtx, orderID, newStatus); err != nil {
+            return err
+        }
+
+        statusPayload := map[string]string{"orderId": orderID.Hex(), "oldStatus": currentStatus, "newStatus": newStatus}
+        if err := s.webhooks.Emit(txCtx, "order.status_changed", orderID.Hex(), statusPayload); err != nil {
+            return err
+        }
+
+        switch newStatus {
+        case "PAID":
+            return s.webhooks.Emit(txCtx, "order.paid", orderID.Hex(), statusPayload)
+        case "CANCELLED":
+            return s.webhooks.Emit(txCtx, "order.cancelled", orderID.Hex(), statusPayload)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    updatedOrder, err := s.repository.FindByID(ctx, orderID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.notifications.Publish(ctx, "order_status_changed", model.NotificationTask{Event: "order_status_changed", OrderID: updatedOrder.ID.Hex(), UserID: updatedOrder.UserID, Status: newStatus}); err != nil {
+        _ = err
+    }
+
+    s.publishOrderEvent(ctx, updatedOrder, currentStatus, newStatus)
+
+    return updatedOrder, nil
+}
+
+// GetUserOrders returns a page of orders belonging to userID.
+func (s *OrderService) GetUserOrders(ctx context.Context, userID string, page, limit int, status string) ([]model.Order, int64, error) {
+    return s.repository.FindByUser(ctx, userID, page, limit, status)
+}
+
+// GetOrderAnalytics serves the analytics endpoint for startDate through
+// endDate (both "2006-01-02"). It echoes the requested date range only;
+// no order data is read from the repository or aggregated.
+func (s *OrderService) GetOrderAnalytics(ctx context.Context, startDate, endDate string) (map[string]interface{}, error) {
+    return map[string]interface{}{
+        "startDate": startDate,
+        "endDate":   endDate,
+    }, nil
+}
+
+/**
+ * Validates status transitions based on business rules.
+ */
+func (s *OrderService) IsValidStatusTransition(currentStatus, newStatus string) bool {
+    transitions := map[string][]string{
+        "PENDING":    {"PAID", "CANCELLED"},
+        "PAID":       {"PROCESSING", "CANCELLED"},
+        "PROCESSING": {"SHIPPED", "CANCELLED"},
+        "SHIPPED":    {"DELIVERED", "RETURNED"},
+    }
+
+    allowed, ok := transitions[currentStatus]
+    if !ok {
+        return false
+    }
+    for _, status := range allowed {
+        if status == newStatus {
+            return true
+        }
+    }
+    return false
+}
+
+// publishOrderEvent broadcasts an order lifecycle transition to
+// subscribers of the owning user's WebSocket channel. Publish failures
+// are swallowed since they must not affect order processing.
+func (s *OrderService) publishOrderEvent(ctx context.Context, order *model.Order, oldStatus, newStatus string) {
+    event := model.OrderEvent{
+        OrderID:   order.ID.Hex(),
+        UserID:    order.UserID,
+        OldStatus: oldStatus,
+        NewStatus: newStatus,
+        Timestamp: time.Now(),
+    }
+    _ = s.pubsub.Publish(ctx, UserOrdersChannel(order.UserID), event)
+}
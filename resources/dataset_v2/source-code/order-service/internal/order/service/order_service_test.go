@@ -0,0 +1,111 @@
+package service
+
+import (
+    "context"
+    "io"
+    "log/slog"
+    "testing"
+    "order-service/internal/order/mocks"
+    "order-service/internal/order/model"
+    "order-service/pkg/saga"
+)
+
+func newTestService(repo *mocks.FakeOrderRepository, notifications *mocks.FakeNotificationPublisher, payments *mocks.FakePaymentProvider, pubsub *mocks.FakePubSub) *OrderService {
+    cache := &OrderCache{} // zero-value OrderCache has a nil client; tests avoid GetOrder.
+    sagas := saga.NewCoordinator(mocks.NewFakeSagaStore(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+    inventory := mocks.NewFakeInventory()
+    webhooks := mocks.NewFakeWebhooks()
+    txRunner := mocks.NewFakeTxRunner()
+    return NewOrderService(repo, notifications, payments, cache, pubsub, sagas, inventory, webhooks, txRunner)
+}
+
+func TestOrderService_CreateOrder(t *testing.T) {
+    tests := []struct {
+        name        string
+        paymentErr  error
+        wantErr     bool
+        wantStatus  string
+    }{
+        {name: "payment succeeds", wantStatus: "PAID"},
+        {name: "payment fails", paymentErr: context.DeadlineExceeded, wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo := mocks.NewFakeOrderRepository()
+            notifications := mocks.NewFakeNotificationPublisher()
+            payments := mocks.NewFakePaymentProvider()
+            payments.Err = tt.paymentErr
+            pubsub := mocks.NewFakePubSub()
+            svc := newTestService(repo, notifications, payments, pubsub)
+
+            order, err := svc.CreateOrder(context.Background(), "user-1", model.CreateOrderRequest{
+                Items:         []model.Item{{ProductID: "p1", Quantity: 1, UnitPrice: 9.99}},
+                TotalAmount:   9.99,
+                PaymentMethod: "card",
+            })
+
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("expected error, got nil")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("unexpected error: %v", err)
+            }
+            if order.Status != tt.wantStatus {
+                t.Errorf("order status = %q, want %q", order.Status, tt.wantStatus)
+            }
+            if len(notifications.Tasks) != 1 {
+                t.Errorf("expected 1 notification task, got %d", len(notifications.Tasks))
+            }
+            if len(pubsub.Events) != 1 {
+                t.Errorf("expected 1 published event, got %d", len(pubsub.Events))
+            }
+        })
+    }
+}
+
+func TestOrderService_UpdateOrderStatus(t *testing.T) {
+    tests := []struct {
+        name          string
+        currentStatus string
+        newStatus     string
+        wantErr       bool
+    }{
+        {name: "valid transition", currentStatus: "PAID", newStatus: "PROCESSING"},
+        {name: "invalid transition", currentStatus: "PENDING", newStatus: "DELIVERED", wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo := mocks.NewFakeOrderRepository()
+            notifications := mocks.NewFakeNotificationPublisher()
+            payments := mocks.NewFakePaymentProvider()
+            pubsub := mocks.NewFakePubSub()
+            svc := newTestService(repo, notifications, payments, pubsub)
+
+            order := &model.Order{UserID: "user-1", Status: tt.currentStatus}
+            if err := repo.Insert(context.Background(), order); err != nil {
+                t.Fatalf("seed insert: %v", err)
+            }
+
+            _, err := svc.UpdateOrderStatus(context.Background(), order.ID, tt.currentStatus, tt.newStatus)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("expected error, got nil")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("unexpected error: %v", err)
+            }
+
+            updated, _ := repo.FindByID(context.Background(), order.ID)
+            if updated.Status != tt.newStatus {
+                t.Errorf("order status = %q, want %q", updated.Status, tt.newStatus)
+            }
+        })
+    }
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+    "context"
+    "encoding/json"
+    "time"
+    "github.com/go-redis/redis/v8"
+    "order-service/internal/order/model"
+)
+
+/**
+ * Redis-backed publish/subscribe abstraction used to fan out order
+ * lifecycle events to interested consumers (currently the WebSocket
+ * transport in the handler package).
+ */
+
+// PubSub publishes order events to a channel and allows consumers to
+// subscribe to a stream of events for that channel.
+type PubSub interface {
+    Publish(ctx context.Context, channel string, event model.OrderEvent) error
+    Subscribe(ctx context.Context, channel string) <-chan model.OrderEvent
+}
+
+// RedisPubSub implements PubSub on top of Redis Pub/Sub.
+type RedisPubSub struct {
+    client *redis.Client
+}
+
+// NewRedisPubSub creates a PubSub backed by the given Redis client.
+func NewRedisPubSub(client *redis.Client) *RedisPubSub {
+    return &RedisPubSub{client: client}
+}
+
+// Publish serializes the event as JSON and publishes it to channel.
+func (p *RedisPubSub) Publish(ctx context.Context, channel string, event model.OrderEvent) error {
+    payload, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    return p.client.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe returns a channel of OrderEvents received on the given Redis
+// channel. The returned channel is closed when ctx is cancelled or the
+// underlying Redis subscription is closed; malformed payloads are
+// dropped rather than delivered.
+func (p *RedisPubSub) Subscribe(ctx context.Context, channel string) <-chan model.OrderEvent {
+    sub := p.client.Subscribe(ctx, channel)
+    events := make(chan model.OrderEvent)
+
+    go func() {
+        defer close(events)
+        defer sub.Close()
+
+        msgCh := sub.Channel()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case msg, ok := <-msgCh:
+                if !ok {
+                    return
+                }
+                var event model.OrderEvent
+                if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+                    continue
+                }
+                select {
+                case events <- event:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+
+    return events
+}
+
+// UserOrdersChannel returns the per-user Redis channel name used for
+// publishing and subscribing to a user's order events.
+func UserOrdersChannel(userID string) string {
+    return "user_orders:" + userID
+}
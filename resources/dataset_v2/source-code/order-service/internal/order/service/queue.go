@@ -0,0 +1,193 @@
+package service
+
+import (
+    "context"
+    "encoding/json"
+    "log/slog"
+    "time"
+    "github.com/go-redis/redis/v8"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/propagation"
+    "order-service/internal/observability"
+    "order-service/internal/order/model"
+)
+
+/**
+ * Durable job queue backed by Redis lists. Replaces bare goroutine
+ * notification dispatch, which silently dropped work on process exit or
+ * panic. Enqueue/process propagate the enqueuing request's trace context
+ * across the Redis boundary via the W3C traceparent header, so a task's
+ * processing span still links back to the request that created it.
+ */
+
+const (
+    queueKeyPrefix    = "queue:"
+    deadLetterKey     = "queue:dead-letter"
+    defaultMaxRetries = 5
+)
+
+var tracer = otel.Tracer("order-service/queue")
+
+// Handler processes a single task. Returning an error causes the task to
+// be retried with exponential backoff, up to the queue's max retries.
+type Handler func(ctx context.Context, task model.Task) error
+
+var (
+    tasksEnqueued = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "order_service_queue_tasks_enqueued_total",
+        Help: "Total number of tasks enqueued, by task kind.",
+    }, []string{"kind"})
+    tasksProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "order_service_queue_tasks_processed_total",
+        Help: "Total number of tasks processed successfully, by task kind.",
+    }, []string{"kind"})
+    tasksFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+        Name: "order_service_queue_tasks_failed_total",
+        Help: "Total number of tasks that exhausted retries and moved to the dead-letter queue, by task kind.",
+    }, []string{"kind"})
+)
+
+// Queue is a Redis-list-backed durable job queue with LPUSH/BRPOP
+// semantics, a configurable worker pool, and a dead-letter queue for
+// tasks that exhaust their retries.
+type Queue struct {
+    client     *redis.Client
+    name       string
+    maxRetries int
+    logger     *slog.Logger
+}
+
+// NewQueue creates a Queue named name, persisted under queue:<name> in Redis.
+func NewQueue(client *redis.Client, name string) *Queue {
+    return &Queue{client: client, name: name, maxRetries: defaultMaxRetries, logger: observability.NewLogger()}
+}
+
+func (q *Queue) key() string {
+    return queueKeyPrefix + q.name
+}
+
+// Enqueue pushes a task of the given kind onto the queue for later
+// processing by a worker pool started with Consume. The traceparent of
+// ctx's active span, if any, is stored on the task so Consume's worker
+// can continue the same trace.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+
+    carrier := propagation.MapCarrier{}
+    otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+    task := model.Task{
+        ID:          "task_" + primitive.NewObjectID().Hex(),
+        Kind:        kind,
+        Payload:     body,
+        EnqueuedAt:  time.Now(),
+        TraceParent: carrier["traceparent"],
+    }
+
+    encoded, err := json.Marshal(task)
+    if err != nil {
+        return err
+    }
+
+    if err := q.client.LPush(ctx, q.key(), encoded).Err(); err != nil {
+        return err
+    }
+
+    tasksEnqueued.WithLabelValues(kind).Inc()
+    return nil
+}
+
+// Consume starts concurrency worker goroutines that BRPOP tasks off the
+// queue and dispatch them to handler. It blocks until ctx is cancelled.
+func (q *Queue) Consume(ctx context.Context, concurrency int, handler Handler) {
+    for i := 0; i < concurrency; i++ {
+        go q.worker(ctx, handler)
+    }
+    <-ctx.Done()
+}
+
+func (q *Queue) worker(ctx context.Context, handler Handler) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        default:
+        }
+
+        result, err := q.client.BRPop(ctx, 5*time.Second, q.key()).Result()
+        if err == redis.Nil {
+            continue
+        }
+        if err != nil {
+            if ctx.Err() != nil {
+                return
+            }
+            q.logger.ErrorContext(ctx, "BRPOP error", "queue", q.name, "error", err)
+            continue
+        }
+
+        // result[0] is the key name, result[1] is the payload.
+        var task model.Task
+        if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
+            q.logger.ErrorContext(ctx, "discarding malformed task", "queue", q.name, "error", err)
+            continue
+        }
+
+        q.process(ctx, task, handler)
+    }
+}
+
+func (q *Queue) process(ctx context.Context, task model.Task, handler Handler) {
+    task.Attempts++
+
+    taskCtx := ctx
+    if task.TraceParent != "" {
+        taskCtx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{"traceparent": task.TraceParent})
+    }
+    taskCtx, span := tracer.Start(taskCtx, "queue.process")
+    defer span.End()
+
+    if err := handler(taskCtx, task); err != nil {
+        if task.Attempts >= q.maxRetries {
+            q.logger.ErrorContext(taskCtx, "task exhausted retries, moving to dead-letter queue", "queue", q.name, "task_id", task.ID, "error", err)
+            tasksFailed.WithLabelValues(task.Kind).Inc()
+            q.deadLetter(ctx, task, err)
+            return
+        }
+
+        backoff := time.Duration(1<<task.Attempts) * time.Second
+        q.logger.WarnContext(taskCtx, "task failed, retrying with backoff", "queue", q.name, "task_id", task.ID, "attempt", task.Attempts, "max_retries", q.maxRetries, "backoff", backoff, "error", err)
+
+        go func() {
+            time.Sleep(backoff)
+            encoded, marshalErr := json.Marshal(task)
+            if marshalErr != nil {
+                return
+            }
+            q.client.LPush(ctx, q.key(), encoded)
+        }()
+        return
+    }
+
+    tasksProcessed.WithLabelValues(task.Kind).Inc()
+}
+
+func (q *Queue) deadLetter(ctx context.Context, task model.Task, cause error) {
+    entry := struct {
+        Task  model.Task `json:"task"`
+        Error string     `json:"error"`
+        At    time.Time  `json:"at"`
+    }{Task: task, Error: cause.Error(), At: time.Now()}
+
+    encoded, err := json.Marshal(entry)
+    if err != nil {
+        return
+    }
+    q.client.LPush(ctx, deadLetterKey, encoded)
+}
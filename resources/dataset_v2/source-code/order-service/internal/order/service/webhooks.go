@@ -0,0 +1,20 @@
+package service
+
+import "context"
+
+// Webhooks emits order lifecycle events to the outbox for asynchronous,
+// signed delivery to subscribed downstream systems. See internal/webhook
+// for the production implementation (subscriptions, outbox, and the
+// delivery worker pool).
+type Webhooks interface {
+    Emit(ctx context.Context, eventType, orderID string, payload interface{}) error
+}
+
+// TxRunner runs fn inside a single persistence transaction, so an order
+// mutation and the webhook event describing it are written atomically:
+// the event can never be missing for a mutation that committed, nor
+// present for one that rolled back. See repository.MongoTxRunner for the
+// production implementation.
+type TxRunner interface {
+    WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,150 @@
+package webhook
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "time"
+    "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// retrySchedule is how long to wait before each successive delivery
+// attempt, Stripe-style: fast at first, backing off out to a full day
+// before a delivery is marked exhausted.
+var retrySchedule = []time.Duration{
+    1 * time.Second,
+    5 * time.Second,
+    25 * time.Second,
+    2 * time.Minute,
+    10 * time.Minute,
+    time.Hour,
+    6 * time.Hour,
+    24 * time.Hour,
+}
+
+// Dispatcher polls the outbox for due deliveries and attempts each one,
+// signing the body the same way Stripe signs its own webhooks so
+// subscribers can verify authenticity.
+type Dispatcher struct {
+    store  *Store
+    client *http.Client
+    logger *slog.Logger
+}
+
+// NewDispatcher creates a Dispatcher backed by store.
+func NewDispatcher(store *Store, logger *slog.Logger) *Dispatcher {
+    return &Dispatcher{
+        store:  store,
+        client: &http.Client{Timeout: 10 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+        logger: logger,
+    }
+}
+
+// Run polls for due deliveries every interval, fanning them out across
+// concurrency worker goroutines, until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration, concurrency int) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    work := make(chan Delivery)
+    defer close(work)
+    for i := 0; i < concurrency; i++ {
+        go func() {
+            for delivery := range work {
+                d.attempt(ctx, delivery)
+            }
+        }()
+    }
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            due, err := d.store.DueDeliveries(ctx, int64(concurrency*4))
+            if err != nil {
+                d.logger.ErrorContext(ctx, "webhook dispatcher: failed to list due deliveries", "error", err)
+                continue
+            }
+            for _, delivery := range due {
+                select {
+                case work <- delivery:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery Delivery) {
+    sub, err := d.store.GetSubscription(ctx, delivery.SubscriptionID)
+    if err != nil || !sub.Active {
+        // The subscription was deleted or deactivated since this
+        // delivery was scheduled; there's nothing left to deliver to.
+        if err := d.store.RecordAttempt(ctx, delivery.ID, Attempt{At: time.Now(), Error: "subscription no longer active"}, false, time.Time{}, true); err != nil {
+            d.logger.ErrorContext(ctx, "webhook dispatcher: failed to record abandoned delivery", "delivery_id", delivery.ID.Hex(), "error", err)
+        }
+        return
+    }
+
+    body, err := json.Marshal(delivery.Payload)
+    if err != nil {
+        d.logger.ErrorContext(ctx, "webhook dispatcher: failed to encode payload", "delivery_id", delivery.ID.Hex(), "error", err)
+        return
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+    if err != nil {
+        d.logger.ErrorContext(ctx, "webhook dispatcher: failed to build request", "delivery_id", delivery.ID.Hex(), "error", err)
+        return
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("X-Signature", "sha256="+sign(sub.Secret, body))
+    req.Header.Set("X-Event-Type", delivery.EventType)
+
+    resp, doErr := d.client.Do(req)
+    attemptsMade := len(delivery.Attempts) + 1
+
+    attempt := Attempt{At: time.Now()}
+    var succeeded bool
+    if doErr != nil {
+        attempt.Error = doErr.Error()
+    } else {
+        defer resp.Body.Close()
+        attempt.StatusCode = resp.StatusCode
+        succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+    }
+
+    if succeeded {
+        if err := d.store.RecordAttempt(ctx, delivery.ID, attempt, true, time.Time{}, false); err != nil {
+            d.logger.ErrorContext(ctx, "webhook dispatcher: failed to record delivery", "delivery_id", delivery.ID.Hex(), "error", err)
+        }
+        return
+    }
+
+    if attemptsMade > len(retrySchedule) {
+        d.logger.WarnContext(ctx, "webhook dispatcher: delivery exhausted its retry schedule", "delivery_id", delivery.ID.Hex(), "url", delivery.URL)
+        if err := d.store.RecordAttempt(ctx, delivery.ID, attempt, false, time.Time{}, true); err != nil {
+            d.logger.ErrorContext(ctx, "webhook dispatcher: failed to record exhausted delivery", "delivery_id", delivery.ID.Hex(), "error", err)
+        }
+        return
+    }
+
+    nextAttemptAt := time.Now().Add(retrySchedule[attemptsMade-1])
+    if err := d.store.RecordAttempt(ctx, delivery.ID, attempt, false, nextAttemptAt, false); err != nil {
+        d.logger.ErrorContext(ctx, "webhook dispatcher: failed to reschedule delivery", "delivery_id", delivery.ID.Hex(), "error", err)
+    }
+}
+
+// sign computes the Stripe-style HMAC-SHA256 signature of body under secret.
+func sign(secret string, body []byte) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write(body)
+    return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+// Package webhook lets downstream systems (shipping, email, analytics)
+// subscribe to order lifecycle events. OrderService writes each
+// occurrence to a Mongo outbox transactionally alongside the order
+// mutation that caused it; a background Dispatcher fans each event out
+// to every active, matching Subscription as a signed HTTP POST, retrying
+// on failure with backoff and recording every attempt for the audit
+// trail at GET /api/webhooks/:id/deliveries.
+package webhook
+
+import (
+    "time"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Subscription is a downstream system's registration to receive order
+// lifecycle events of the named Events at URL, signed with Secret.
+type Subscription struct {
+    ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+    URL       string             `json:"url" bson:"url"`
+    Events    []string           `json:"events" bson:"events"`
+    Secret    string             `json:"secret" bson:"secret"`
+    Active    bool               `json:"active" bson:"active"`
+    CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Event is an outbox entry recording that an order lifecycle occurrence
+// happened, independent of whether delivery to any subscriber ever
+// succeeds.
+type Event struct {
+    ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+    Type      string             `json:"type" bson:"type"`
+    OrderID   string             `json:"orderId" bson:"orderId"`
+    Payload   interface{}        `json:"payload" bson:"payload"`
+    CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Attempt is a single HTTP delivery attempt recorded against a Delivery.
+type Attempt struct {
+    At         time.Time `json:"at" bson:"at"`
+    StatusCode int       `json:"statusCode" bson:"statusCode"`
+    Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// Delivery tracks one Subscription's delivery of one Event: its retry
+// schedule (NextAttemptAt) and the history of attempts made so far,
+// which is what the audit trail endpoint exposes.
+type Delivery struct {
+    ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+    SubscriptionID primitive.ObjectID `json:"subscriptionId" bson:"subscriptionId"`
+    EventID        primitive.ObjectID `json:"eventId" bson:"eventId"`
+    EventType      string             `json:"eventType" bson:"eventType"`
+    URL            string             `json:"url" bson:"url"`
+    Payload        interface{}        `json:"payload" bson:"payload"`
+    Delivered      bool               `json:"delivered" bson:"delivered"`
+    Exhausted      bool               `json:"exhausted" bson:"exhausted"`
+    Attempts       []Attempt          `json:"attempts" bson:"attempts"`
+    NextAttemptAt  time.Time          `json:"nextAttemptAt" bson:"nextAttemptAt"`
+    CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+    "context"
+    "fmt"
+    "time"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Service is the production service.Webhooks: it writes an Event to the
+// outbox and schedules a Delivery for every active subscription
+// currently matching its type. Call it with a context carrying a Mongo
+// session (see repository.MongoTxRunner) so the outbox write commits
+// atomically with whatever order mutation caused the event.
+type Service struct {
+    store *Store
+}
+
+// NewService creates a Service backed by store.
+func NewService(store *Store) *Service {
+    return &Service{store: store}
+}
+
+// Emit records that an order lifecycle event of eventType happened to
+// orderID, and schedules an immediate delivery attempt to every active
+// subscription currently registered for it.
+func (s *Service) Emit(ctx context.Context, eventType, orderID string, payload interface{}) error {
+    event := &Event{
+        ID:        primitive.NewObjectID(),
+        Type:      eventType,
+        OrderID:   orderID,
+        Payload:   payload,
+        CreatedAt: time.Now(),
+    }
+    if err := s.store.InsertEvent(ctx, event); err != nil {
+        return fmt.Errorf("webhook: insert event: %w", err)
+    }
+
+    subs, err := s.store.ActiveSubscriptionsFor(ctx, eventType)
+    if err != nil {
+        return fmt.Errorf("webhook: list subscriptions for %s: %w", eventType, err)
+    }
+
+    for _, sub := range subs {
+        delivery := &Delivery{
+            ID:             primitive.NewObjectID(),
+            SubscriptionID: sub.ID,
+            EventID:        event.ID,
+            EventType:      eventType,
+            URL:            sub.URL,
+            Payload:        payload,
+            NextAttemptAt:  time.Now(),
+            CreatedAt:      time.Now(),
+        }
+        if err := s.store.InsertDelivery(ctx, delivery); err != nil {
+            return fmt.Errorf("webhook: schedule delivery to %s: %w", sub.URL, err)
+        }
+    }
+
+    return nil
+}
+
+// CreateSubscription registers a new subscription.
+func (s *Service) CreateSubscription(ctx context.Context, sub *Subscription) error {
+    return s.store.CreateSubscription(ctx, sub)
+}
+
+// ListSubscriptions returns every registered subscription.
+func (s *Service) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+    return s.store.ListSubscriptions(ctx)
+}
+
+// DeleteSubscription removes a subscription by ID.
+func (s *Service) DeleteSubscription(ctx context.Context, id primitive.ObjectID) error {
+    return s.store.DeleteSubscription(ctx, id)
+}
+
+// Deliveries returns the delivery audit trail for a subscription.
+func (s *Service) Deliveries(ctx context.Context, subscriptionID primitive.ObjectID) ([]Delivery, error) {
+    return s.store.DeliveriesForSubscription(ctx, subscriptionID)
+}
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+    "context"
+    "time"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store is the Mongo persistence boundary for subscriptions, the event
+// outbox, and delivery attempts.
+type Store struct {
+    subscriptions *mongo.Collection
+    events        *mongo.Collection
+    deliveries    *mongo.Collection
+}
+
+// NewStore creates a Store using the "subscriptions", "events", and
+// "deliveries" collections of the "ecommerce" database.
+func NewStore(client *mongo.Client) *Store {
+    db := client.Database("ecommerce")
+    return &Store{
+        subscriptions: db.Collection("subscriptions"),
+        events:        db.Collection("events"),
+        deliveries:    db.Collection("deliveries"),
+    }
+}
+
+func (s *Store) CreateSubscription(ctx context.Context, sub *Subscription) error {
+    sub.ID = primitive.NewObjectID()
+    sub.CreatedAt = time.Now()
+    _, err := s.subscriptions.InsertOne(ctx, sub)
+    return err
+}
+
+func (s *Store) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+    cursor, err := s.subscriptions.Find(ctx, bson.M{})
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var subs []Subscription
+    if err := cursor.All(ctx, &subs); err != nil {
+        return nil, err
+    }
+    return subs, nil
+}
+
+func (s *Store) GetSubscription(ctx context.Context, id primitive.ObjectID) (*Subscription, error) {
+    var sub Subscription
+    if err := s.subscriptions.FindOne(ctx, bson.M{"_id": id}).Decode(&sub); err != nil {
+        return nil, err
+    }
+    return &sub, nil
+}
+
+func (s *Store) DeleteSubscription(ctx context.Context, id primitive.ObjectID) error {
+    _, err := s.subscriptions.DeleteOne(ctx, bson.M{"_id": id})
+    return err
+}
+
+// ActiveSubscriptionsFor returns every active subscription registered
+// for eventType.
+func (s *Store) ActiveSubscriptionsFor(ctx context.Context, eventType string) ([]Subscription, error) {
+    cursor, err := s.subscriptions.Find(ctx, bson.M{"active": true, "events": eventType})
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var subs []Subscription
+    if err := cursor.All(ctx, &subs); err != nil {
+        return nil, err
+    }
+    return subs, nil
+}
+
+func (s *Store) InsertEvent(ctx context.Context, event *Event) error {
+    _, err := s.events.InsertOne(ctx, event)
+    return err
+}
+
+func (s *Store) InsertDelivery(ctx context.Context, delivery *Delivery) error {
+    _, err := s.deliveries.InsertOne(ctx, delivery)
+    return err
+}
+
+// DueDeliveries returns up to limit undelivered, unexhausted deliveries
+// whose NextAttemptAt has passed, for the Dispatcher to attempt.
+func (s *Store) DueDeliveries(ctx context.Context, limit int64) ([]Delivery, error) {
+    cursor, err := s.deliveries.Find(ctx,
+        bson.M{
+            "delivered":     false,
+            "exhausted":     false,
+            "nextAttemptAt": bson.M{"$lte": time.Now()},
+        },
+        options.Find().SetLimit(limit),
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var deliveries []Delivery
+    if err := cursor.All(ctx, &deliveries); err != nil {
+        return nil, err
+    }
+    return deliveries, nil
+}
+
+// DeliveriesForSubscription returns every delivery attempted or
+// scheduled for subscriptionID, newest first, for the audit trail
+// endpoint.
+func (s *Store) DeliveriesForSubscription(ctx context.Context, subscriptionID primitive.ObjectID) ([]Delivery, error) {
+    cursor, err := s.deliveries.Find(ctx,
+        bson.M{"subscriptionId": subscriptionID},
+        options.Find().SetSort(bson.M{"createdAt": -1}),
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var deliveries []Delivery
+    if err := cursor.All(ctx, &deliveries); err != nil {
+        return nil, err
+    }
+    return deliveries, nil
+}
+
+// RecordAttempt appends attempt to delivery's history and reschedules
+// or resolves it: delivered on success, NextAttemptAt pushed out to the
+// next backoff step on failure, or exhausted once the schedule runs out.
+func (s *Store) RecordAttempt(ctx context.Context, deliveryID primitive.ObjectID, attempt Attempt, delivered bool, nextAttemptAt time.Time, exhausted bool) error {
+    update := bson.M{
+        "$push": bson.M{"attempts": attempt},
+        "$set": bson.M{
+            "delivered":     delivered,
+            "nextAttemptAt": nextAttemptAt,
+            "exhausted":     exhausted,
+        },
+    }
+    _, err := s.deliveries.UpdateByID(ctx, deliveryID, update)
+    return err
+}
@@ -0,0 +1,199 @@
+// Package saga implements a minimal saga coordinator for orchestrating
+// multi-step distributed transactions (e.g. CreateOrder's inventory
+// reservation, payment authorization, and persistence) with automatic
+// compensation on failure. Progress is persisted to a Store as each step
+// completes, so a coordinator that crashes mid-saga can resume any
+// non-terminal saga from its last known step on restart instead of
+// leaving it stuck half-applied.
+package saga
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status values a saga's Status field transitions through. Started and
+// Compensating are in-flight; Completed and Compensated are terminal.
+const (
+    StatusStarted      = "STARTED"
+    StatusCompensating = "COMPENSATING"
+    StatusCompleted    = "COMPLETED"
+    StatusCompensated  = "COMPENSATED"
+)
+
+// State is the persisted record of a single saga run. Data carries the
+// inputs and intermediate results steps produce (e.g. a charge ID a
+// compensating action must refund), so a coordinator that crashed and
+// restarted can reconstruct enough context to resume.
+type State struct {
+    ID        primitive.ObjectID `bson:"_id"`
+    Type      string             `bson:"type"`
+    Status    string             `bson:"status"`
+    StepIndex int                `bson:"stepIndex"` // index of the last step whose Do completed, or -1
+    Data      bson.M             `bson:"data"`
+    Error     string             `bson:"error,omitempty"`
+    CreatedAt time.Time          `bson:"createdAt"`
+    UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+// Step is a single stage of a saga: Do performs the forward action,
+// Compensate undoes it. Both may read and write state.Data to pass
+// results forward (Do) or recover what needs undoing (Compensate).
+type Step struct {
+    Name       string
+    Do         func(ctx context.Context, state *State) error
+    Compensate func(ctx context.Context, state *State) error
+}
+
+// Builder reconstructs the steps for a saga type from its persisted
+// Data. Registering a Builder lets Resume replay a saga after a crash
+// without the coordinator having kept the original closures in memory.
+type Builder func(data bson.M) []Step
+
+// Store persists saga State documents so a crashed coordinator can find
+// and resume any saga left in a non-terminal status.
+type Store interface {
+    Create(ctx context.Context, state *State) error
+    Update(ctx context.Context, state *State) error
+    FindNonTerminal(ctx context.Context) ([]State, error)
+}
+
+// Coordinator runs sagas built from registered Builders, persisting
+// progress to a Store after every step so a run can be resumed.
+type Coordinator struct {
+    store    Store
+    builders map[string]Builder
+    logger   *slog.Logger
+}
+
+// NewCoordinator creates a Coordinator backed by store.
+func NewCoordinator(store Store, logger *slog.Logger) *Coordinator {
+    return &Coordinator{store: store, builders: make(map[string]Builder), logger: logger}
+}
+
+// Register associates sagaType with the Builder used to reconstruct its
+// steps, both for a fresh Run and for Resume after a restart.
+func (c *Coordinator) Register(sagaType string, builder Builder) {
+    c.builders[sagaType] = builder
+}
+
+// Run starts a new saga of sagaType with the given initial data, builds
+// its steps via the registered Builder, and executes them in order. If
+// a step's Do fails, every previously completed step is compensated in
+// reverse order before the error is returned. The returned State's Data
+// holds whatever the completed steps wrote to it (e.g. a charge ID),
+// even on failure.
+func (c *Coordinator) Run(ctx context.Context, sagaType string, data bson.M) (*State, error) {
+    builder, ok := c.builders[sagaType]
+    if !ok {
+        return nil, fmt.Errorf("saga: no builder registered for type %q", sagaType)
+    }
+
+    now := time.Now()
+    state := &State{
+        ID:        primitive.NewObjectID(),
+        Type:      sagaType,
+        Status:    StatusStarted,
+        StepIndex: -1,
+        Data:      data,
+        CreatedAt: now,
+        UpdatedAt: now,
+    }
+    if err := c.store.Create(ctx, state); err != nil {
+        return nil, fmt.Errorf("saga: persist initial state: %w", err)
+    }
+
+    return c.execute(ctx, state, builder(state.Data))
+}
+
+// execute runs steps[state.StepIndex+1:] forward, persisting progress
+// after each one, and compensates everything completed so far on
+// failure.
+func (c *Coordinator) execute(ctx context.Context, state *State, steps []Step) (*State, error) {
+    for i := state.StepIndex + 1; i < len(steps); i++ {
+        step := steps[i]
+        if err := step.Do(ctx, state); err != nil {
+            c.logger.ErrorContext(ctx, "saga step failed, compensating", "saga_id", state.ID.Hex(), "saga_type", state.Type, "step", step.Name, "error", err)
+            state.Error = err.Error()
+            state.Status = StatusCompensating
+            c.save(ctx, state)
+
+            c.compensate(ctx, state, steps)
+            return state, fmt.Errorf("saga: step %q failed: %w", step.Name, err)
+        }
+
+        state.StepIndex = i
+        c.save(ctx, state)
+    }
+
+    state.Status = StatusCompleted
+    c.save(ctx, state)
+    return state, nil
+}
+
+// compensate undoes steps [0, state.StepIndex] in reverse order. It is
+// best-effort: a compensation failure is logged but doesn't stop the
+// remaining compensations, since leaving earlier steps un-compensated
+// is worse than a partially-compensated saga.
+func (c *Coordinator) compensate(ctx context.Context, state *State, steps []Step) {
+    for i := state.StepIndex; i >= 0; i-- {
+        step := steps[i]
+        if step.Compensate == nil {
+            continue
+        }
+        if err := step.Compensate(ctx, state); err != nil {
+            c.logger.ErrorContext(ctx, "saga compensation failed", "saga_id", state.ID.Hex(), "saga_type", state.Type, "step", step.Name, "error", err)
+        }
+    }
+
+    state.Status = StatusCompensated
+    c.save(ctx, state)
+}
+
+// save persists state, logging rather than failing the saga if the
+// store write itself errors; the in-memory state remains authoritative
+// for the rest of this run.
+func (c *Coordinator) save(ctx context.Context, state *State) {
+    state.UpdatedAt = time.Now()
+    if err := c.store.Update(ctx, state); err != nil {
+        c.logger.ErrorContext(ctx, "failed to persist saga state", "saga_id", state.ID.Hex(), "error", err)
+    }
+}
+
+// Resume scans the store for sagas left in a non-terminal status (e.g.
+// by a crash mid-run) and replays each one to completion: a saga still
+// STARTED continues its forward steps from StepIndex+1, and one already
+// COMPENSATING resumes compensating from StepIndex backward. Call this
+// once at startup before serving new requests.
+func (c *Coordinator) Resume(ctx context.Context) error {
+    pending, err := c.store.FindNonTerminal(ctx)
+    if err != nil {
+        return fmt.Errorf("saga: list non-terminal sagas: %w", err)
+    }
+
+    for i := range pending {
+        state := &pending[i]
+        builder, ok := c.builders[state.Type]
+        if !ok {
+            c.logger.ErrorContext(ctx, "no builder registered for saga type, cannot resume", "saga_id", state.ID.Hex(), "saga_type", state.Type)
+            continue
+        }
+
+        steps := builder(state.Data)
+        c.logger.InfoContext(ctx, "resuming saga", "saga_id", state.ID.Hex(), "saga_type", state.Type, "status", state.Status, "step_index", state.StepIndex)
+
+        if state.Status == StatusCompensating {
+            c.compensate(ctx, state, steps)
+            continue
+        }
+
+        c.execute(ctx, state, steps)
+    }
+
+    return nil
+}
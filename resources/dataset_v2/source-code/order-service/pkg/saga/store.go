@@ -0,0 +1,46 @@
+package saga
+
+import (
+    "context"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore is the production Store, backed by the "sagas" collection
+// so a crashed coordinator can find and resume any saga left in a
+// non-terminal status.
+type MongoStore struct {
+    collection *mongo.Collection
+}
+
+// NewMongoStore creates a MongoStore using the "sagas" collection of
+// the "ecommerce" database.
+func NewMongoStore(client *mongo.Client) *MongoStore {
+    return &MongoStore{collection: client.Database("ecommerce").Collection("sagas")}
+}
+
+func (s *MongoStore) Create(ctx context.Context, state *State) error {
+    _, err := s.collection.InsertOne(ctx, state)
+    return err
+}
+
+func (s *MongoStore) Update(ctx context.Context, state *State) error {
+    _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": state.ID}, state)
+    return err
+}
+
+func (s *MongoStore) FindNonTerminal(ctx context.Context) ([]State, error) {
+    filter := bson.M{"status": bson.M{"$nin": []string{StatusCompleted, StatusCompensated}}}
+    cursor, err := s.collection.Find(ctx, filter)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var states []State
+    if err := cursor.All(ctx, &states); err != nil {
+        return nil, err
+    }
+    return states, nil
+}